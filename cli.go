@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/config"
+	"calendar-wallpaper/internal/devicecatalog"
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/heatmapstore"
+	"calendar-wallpaper/internal/rendering"
+	"calendar-wallpaper/internal/usecase"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04"
+)
+
+type renderJob struct {
+	device string
+	date   time.Time
+	out    string
+}
+
+func runRender(args []string, stdout io.Writer) error {
+	fl := flag.NewFlagSet("render", flag.ContinueOnError)
+
+	var p usecase.RenderParams
+	fl.StringVar(&p.DeviceKey, "device", "iphone-15", "device key")
+	fl.StringVar(&p.Lang, "lang", "en", "language")
+	fl.StringVar(&p.Weekends, "weekends", "off", "weekend highlight: off|gray|green|blue|red")
+	fl.StringVar(&p.DayStyle, "style", "dots", "day style: dots|bars|numbers")
+	fl.IntVar(&p.Timezone, "timezone", 0, "UTC offset in hours")
+	fl.IntVar(&p.SizePercent, "size", 100, "UI size in percent (80-130)")
+	fl.StringVar(&p.BgStyle, "bg", "ios", "background: plain|gradient|noise|ios")
+	fl.StringVar(&p.BgColor, "color", "black", "background color name or #rrggbb")
+	fl.StringVar(&p.Widgets, "widgets", "none", "lock-screen widgets: none|inline|row")
+	fl.StringVar(&p.Screen, "screen", "lock", "target screen: lock|home")
+	fl.StringVar(&p.HomeStyle, "home-style", "dock", "home screen variant: dock|status|dimmed")
+	fl.BoolVar(&p.Preview, "preview", false, "draw the safe-zone preview overlay")
+	fl.StringVar(&p.WeekStart, "week-start", "mon", "first day of the week: mon|sun|sat")
+	fl.BoolVar(&p.WeekNumbers, "weeknums", false, "show ISO week numbers next to each month")
+	fl.StringVar(&p.Mode, "mode", "months", "layout: months|month|week|heatmap")
+	fl.StringVar(&p.Granularity, "granularity", "", "render as of the start of the current day|hour|minute; defaults to hour for week, day otherwise")
+	fl.BoolVar(&p.Weekdays, "weekdays", false, "label the day columns with weekday initials")
+	fl.StringVar(&p.Font, "font", "", "font family for all text, e.g. sf-pro|go|go-mono|dejavu-serif")
+	fl.StringVar(&p.FontWeight, "font-weight", "", "font weight: regular|medium|bold or 100-900")
+	fl.StringVar(&p.TitleFont, "title-font", "", "font family for month titles, overrides -font")
+	fl.StringVar(&p.TitleWeight, "title-weight", "", "font weight for month titles")
+	fl.StringVar(&p.NumberFont, "number-font", "", "font family for day numbers, overrides -font")
+	fl.StringVar(&p.NumberWeight, "number-weight", "", "font weight for day numbers")
+	fl.StringVar(&p.FooterFont, "footer-font", "", "font family for the footer, overrides -font")
+	fl.StringVar(&p.FooterWeight, "footer-weight", "", "font weight for the footer")
+	fl.StringVar(&p.Footer, "footer", "", "footer template, e.g. \"{days_left} days left, week {week}\"; {date:LAYOUT} times are as of the start of the -granularity period")
+	fl.StringVar(&p.FooterPosition, "footer-pos", "below", "footer position: below|above|hidden")
+	fl.StringVar(&p.FooterStyle, "footer-style", "text", "footer style: text|bar")
+	countdowns := fl.String("countdown", "", "countdowns for {countdown:label}, as label:YYYY-MM-DD[,...]")
+	events := fl.String("event", "", "days to mark in month mode, as YYYY-MM-DD or yearly MM-DD[,...]")
+	heatmapFile := fl.String("heatmap-file", "", "heatmap mode: CSV (date,value) or JSON ({\"YYYY-MM-DD\": value}) file with the day values")
+	fl.StringVar(&p.HeatmapColor, "heat-color", "green", "heatmap accent: gray|green|blue|red|today or #rrggbb")
+	fl.IntVar(&p.Width, "width", 0, "custom device width in pixels, overrides -device")
+	fl.IntVar(&p.Height, "height", 0, "custom device height in pixels, overrides -device")
+	fl.Float64Var(&p.ClockRatio, "clock-ratio", 0, "custom device: clock zone height as a fraction of the screen")
+	fl.Float64Var(&p.ButtonsRatio, "buttons-ratio", 0, "custom device: top of the buttons zone as a fraction of the screen")
+	fl.IntVar(&p.Inset, "inset", 0, "custom device: bottom inset in pixels")
+
+	date := fl.String("date", "", "render as of this date (YYYY-MM-DD or YYYY-MM-DDTHH:MM), defaults to today")
+	out := fl.String("out", "wallpaper.png", "output file, or output directory in batch mode")
+	devices := fl.String("devices", "", "batch mode: comma-separated device keys or \"all\"")
+	dates := fl.String("dates", "", "batch mode: date range YYYY-MM-DD..YYYY-MM-DD")
+	jobs := fl.Int("jobs", runtime.NumCPU(), "batch mode: parallel renders")
+
+	if err := fl.Parse(args); err != nil {
+		return err
+	}
+	if fl.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fl.Args())
+	}
+	if *countdowns != "" {
+		p.Countdowns = []string{*countdowns}
+	}
+	if *events != "" {
+		p.Events = []string{*events}
+	}
+	heatmaps := &heatmapstore.Memory{}
+	if *heatmapFile != "" {
+		data, err := os.ReadFile(*heatmapFile)
+		if err != nil {
+			return err
+		}
+		values, err := domain.ParseHeatmapValues(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *heatmapFile, err)
+		}
+		p.Heatmap = "cli"
+		if err := heatmaps.Save(context.Background(), p.Heatmap, values); err != nil {
+			return err
+		}
+	}
+
+	cfg := config.Load()
+	if cfg.DevicesFile != "" {
+		catalog, err := devicecatalog.Load(cfg.DevicesFile)
+		if err != nil {
+			return err
+		}
+		domain.SetDevices(catalog)
+	}
+	fsys := assets.New(embeddedAssets, cfg.AssetsDir)
+	if err := assets.Verify(fsys); err != nil {
+		return err
+	}
+	renderer, err := rendering.NewRenderer(fsys)
+	if err != nil {
+		return err
+	}
+	if err := renderer.SelfTest(); err != nil {
+		return err
+	}
+
+	batch := *devices != "" || *dates != ""
+	if !batch {
+		day, err := parseDateOrToday(*date, p.Location())
+		if err != nil {
+			return err
+		}
+		job := renderJob{device: p.DeviceKey, date: day, out: *out}
+		if err := renderOne(renderer, heatmaps, p, job); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, job.out)
+		return nil
+	}
+
+	if *devices != "" && (p.Width != 0 || p.Height != 0) {
+		return errors.New("-devices cannot be combined with a custom -width/-height")
+	}
+	deviceKeys, err := parseDevices(*devices, p.DeviceKey)
+	if err != nil {
+		return err
+	}
+	days, err := parseDates(*dates, *date, p.Location())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+
+	// A custom size is named by its dimensions rather than the unused -device.
+	var custom string
+	if p.Width != 0 || p.Height != 0 {
+		device, err := p.Device()
+		if err != nil {
+			return err
+		}
+		custom = fmt.Sprintf("custom-%dx%d", device.Width, device.Height)
+	}
+
+	var batchJobs []renderJob
+	for _, key := range deviceKeys {
+		label := key
+		if custom != "" {
+			label = custom
+		}
+		for _, day := range days {
+			name := fmt.Sprintf("%s_%s.png", label, day.Format(dateLayout))
+			batchJobs = append(batchJobs, renderJob{device: key, date: day, out: filepath.Join(*out, name)})
+		}
+	}
+
+	return renderBatch(renderer, heatmaps, p, batchJobs, *jobs, stdout)
+}
+
+func renderBatch(renderer usecase.Renderer, heatmaps usecase.HeatmapStore, p usecase.RenderParams, jobs []renderJob, workers int, stdout io.Writer) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan renderJob)
+	errs := make(chan error, len(jobs))
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if err := renderOne(renderer, heatmaps, p, job); err != nil {
+					errs <- fmt.Errorf("%s: %w", job.out, err)
+					continue
+				}
+				mu.Lock()
+				fmt.Fprintln(stdout, job.out)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+	close(errs)
+
+	var all []error
+	for err := range errs {
+		all = append(all, err)
+	}
+	return errors.Join(all...)
+}
+
+func renderOne(renderer usecase.Renderer, heatmaps usecase.HeatmapStore, p usecase.RenderParams, job renderJob) error {
+	service := usecase.Service{
+		Clock:    usecase.FixedClock{Time: job.date},
+		Renderer: renderer,
+		Theme:    domain.IOSTheme(),
+		Heatmaps: heatmaps,
+	}
+	p.DeviceKey = job.device
+
+	img, err := service.RenderWallpaper(context.Background(), p)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(job.out); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(job.out)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func parseDevices(v, fallback string) ([]string, error) {
+	if v == "" {
+		return []string{fallback}, nil
+	}
+	if v == "all" {
+		// Aliases share a profile, so they would render identical images.
+		return domain.Devices().ProfileKeys(), nil
+	}
+
+	var keys []string
+	for _, key := range strings.Split(v, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := domain.Devices().Lookup(key); !ok {
+			return nil, fmt.Errorf("unknown device %q", key)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no devices given")
+	}
+	return keys, nil
+}
+
+func parseDates(rangeValue, single string, loc *time.Location) ([]time.Time, error) {
+	if rangeValue == "" {
+		day, err := parseDateOrToday(single, loc)
+		if err != nil {
+			return nil, err
+		}
+		return []time.Time{day}, nil
+	}
+
+	from, to, ok := strings.Cut(rangeValue, "..")
+	if !ok {
+		from, to = rangeValue, rangeValue
+	}
+	start, err := parseDate(from, loc)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDate(to, loc)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("date range %q ends before it starts", rangeValue)
+	}
+
+	var days []time.Time
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+func parseDateOrToday(v string, loc *time.Location) (time.Time, error) {
+	if v == "" {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location()), nil
+	}
+	return parseDate(v, loc)
+}
+
+// Dates are pinned to local noon so the service's timezone shift cannot
+// move them onto a neighbouring day; a time of day may be given for the
+// week view.
+func parseDate(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(dateTimeLayout, strings.TrimSpace(v), loc); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseInLocation(dateLayout, strings.TrimSpace(v), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %w", v, err)
+	}
+	return d.Add(12 * time.Hour), nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/usecase"
+)
+
+func TestParseDevices(t *testing.T) {
+	all, err := parseDevices("all", "iphone-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[domain.DeviceProfile]string)
+	for _, key := range all {
+		d, ok := domain.Devices().Lookup(key)
+		if !ok {
+			t.Fatalf("all: unknown key %q", key)
+		}
+		d.Key = ""
+		if other, dup := seen[d]; dup {
+			t.Errorf("all: %s and %s are the same profile", other, key)
+		}
+		seen[d] = key
+	}
+	if len(all) != len(domain.Devices().ProfileKeys()) {
+		t.Errorf("all: %d keys, want one per profile", len(all))
+	}
+
+	tests := []struct {
+		v       string
+		want    []string
+		wantErr string
+	}{
+		{"", []string{"iphone-15"}, ""},
+		{"iphone-se-1, iphone-xr,", []string{"iphone-se-1", "iphone-xr"}, ""},
+		{"iphone-15,nokia-3310", nil, `unknown device "nokia-3310"`},
+		{" , ", nil, "no devices given"},
+	}
+	for _, tt := range tests {
+		got, err := parseDevices(tt.v, "iphone-15")
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseDevices(%q) error = %v, want %q", tt.v, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("parseDevices(%q) = %v, %v; want %v", tt.v, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	day := func(s string) string { return s + " 12:00 +0300" }
+	tests := []struct {
+		rng, single string
+		want        []string
+		wantErr     bool
+	}{
+		{"", "2026-10-19", []string{day("2026-10-19")}, false},
+		{"", "2026-10-19T07:30", []string{"2026-10-19 07:30 +0300"}, false},
+		{"2026-10-19", "", []string{day("2026-10-19")}, false},
+		{"2026-12-30..2027-01-02", "", []string{day("2026-12-30"), day("2026-12-31"), day("2027-01-01"), day("2027-01-02")}, false},
+		{"2024-02-28..2024-03-01", "ignored", []string{day("2024-02-28"), day("2024-02-29"), day("2024-03-01")}, false},
+		{"2026-10-19..2026-10-18", "", nil, true},
+		{"2026-10-19..tomorrow", "", nil, true},
+		{"", "19.10.2026", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDates(tt.rng, tt.single, usecase.RenderParams{Timezone: 3}.Location())
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDates(%q, %q) = %v, want error", tt.rng, tt.single, got)
+			}
+			continue
+		}
+		var formatted []string
+		for _, d := range got {
+			formatted = append(formatted, d.Format("2006-01-02 15:04 -0700"))
+		}
+		if err != nil || !slices.Equal(formatted, tt.want) {
+			t.Errorf("parseDates(%q, %q) = %v, %v; want %v", tt.rng, tt.single, formatted, err, tt.want)
+		}
+	}
+
+	got, err := parseDates("", "", time.UTC)
+	if err != nil || len(got) != 1 || got[0].Hour() != 12 || got[0].YearDay() != time.Now().UTC().YearDay() {
+		t.Errorf("parseDates with no date = %v, %v; want today at noon", got, err)
+	}
+
+	// Offsets are clamped the way the service clamps them, so the date
+	// stays the one that was asked for.
+	for tz, want := range map[int]string{99: "+1400", -99: "-1200"} {
+		got, err := parseDates("", "2026-10-19", usecase.RenderParams{Timezone: tz}.Location())
+		if err != nil || len(got) != 1 || got[0].Format("2006-01-02 -0700") != "2026-10-19 "+want {
+			t.Errorf("parseDates with timezone %d = %v, %v; want 2026-10-19 %s", tz, got, err, want)
+		}
+	}
+}
+
+func TestRenderBatch(t *testing.T) {
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	err := runRender([]string{
+		"-devices", "iphone-se-1,iphone-xr",
+		"-dates", "2026-12-31..2027-01-01",
+		"-bg", "plain",
+		"-jobs", "2",
+		"-out", dir,
+	}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"iphone-se-1_2026-12-31.png",
+		"iphone-se-1_2027-01-01.png",
+		"iphone-xr_2026-12-31.png",
+		"iphone-xr_2027-01-01.png",
+	}
+	assertPNGs(t, dir, want)
+	if lines := strings.Count(stdout.String(), "\n"); lines != len(want) {
+		t.Errorf("printed %d paths, want %d:\n%s", lines, len(want), stdout.String())
+	}
+
+	se, _ := domain.Devices().Lookup("iphone-se-1")
+	f, err := os.Open(filepath.Join(dir, want[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, err := png.DecodeConfig(f)
+	if err != nil || cfg.Width != se.Width || cfg.Height != se.Height {
+		t.Errorf("%s is %dx%d (%v), want %dx%d", want[0], cfg.Width, cfg.Height, err, se.Width, se.Height)
+	}
+}
+
+func TestRenderBatchCustomSize(t *testing.T) {
+	dir := t.TempDir()
+	err := runRender([]string{
+		"-width", "320", "-height", "640",
+		"-dates", "2026-10-19..2026-10-20",
+		"-bg", "plain",
+		"-out", dir,
+	}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPNGs(t, dir, []string{"custom-320x640_2026-10-19.png", "custom-320x640_2026-10-20.png"})
+
+	err = runRender([]string{"-width", "320", "-height", "640", "-devices", "all", "-out", dir}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("-devices with a custom size: want error")
+	}
+}
+
+func assertPNGs(t *testing.T, dir string, want []string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("wrote %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,6 @@
+package main
+
+import "embed"
+
+//go:embed fonts web
+var embeddedAssets embed.FS
@@ -0,0 +1,55 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+const (
+	FontDir    = "fonts"
+	FontPath   = "fonts/SFPRODISPLAYBOLD.OTF"
+	IndexPath  = "web/index.html"
+	ImagesPath = "web/images"
+
+	// FallbackFontDir holds per-script fonts: a bundled Arabic and Hebrew
+	// subset and optional Noto faces; see its README. It is not checked by
+	// Verify.
+	FallbackFontDir = "fonts/fallback"
+)
+
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func New(embedded fs.FS, overrideDir string) fs.FS {
+	if overrideDir == "" {
+		return embedded
+	}
+	return overlayFS{
+		override: os.DirFS(overrideDir),
+		base:     embedded,
+	}
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.override.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.base.Open(name)
+}
+
+func Verify(fsys fs.FS) error {
+	for _, name := range []string{FontPath, IndexPath, ImagesPath} {
+		if _, err := fs.Stat(fsys, name); err != nil {
+			return fmt.Errorf("asset %s: %w", name, err)
+		}
+	}
+	return nil
+}
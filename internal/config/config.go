@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	Addr            string
+	AssetsDir       string
+	ShutdownTimeout time.Duration
+
+	DevicesFile         string
+	DevicesPollInterval time.Duration
+
+	LogFormat    string
+	LogLevel     string
+	TraceExport  string
+	OTLPEndpoint string
+
+	RateLimitRPS       float64
+	RateLimitBurst     int
+	MaxConcurrent      int
+	RenderQueueTimeout time.Duration
+
+	HeatmapDir   string
+	HeatmapToken string
+}
+
+func Load() Config {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	return Config{
+		Addr:            ":" + port,
+		AssetsDir:       os.Getenv("ASSETS_DIR"),
+		ShutdownTimeout: durationEnv("SHUTDOWN_TIMEOUT", 15*time.Second),
+
+		DevicesFile:         os.Getenv("DEVICES_FILE"),
+		DevicesPollInterval: durationEnv("DEVICES_POLL_INTERVAL", 5*time.Second),
+
+		LogFormat:    stringEnv("LOG_FORMAT", "json"),
+		LogLevel:     stringEnv("LOG_LEVEL", "info"),
+		TraceExport:  stringEnv("TRACE_EXPORT", "off"),
+		OTLPEndpoint: stringEnv("OTLP_ENDPOINT", "http://localhost:4318/v1/traces"),
+
+		RateLimitRPS:       floatEnv("RATE_LIMIT_RPS", 1),
+		RateLimitBurst:     intEnv("RATE_LIMIT_BURST", 10),
+		MaxConcurrent:      intEnv("MAX_CONCURRENT_RENDERS", runtime.NumCPU()),
+		RenderQueueTimeout: durationEnv("RENDER_QUEUE_TIMEOUT", 2*time.Second),
+
+		HeatmapDir:   stringEnv("HEATMAP_DIR", "/var/lib/calendar-wallpaper/heatmaps"),
+		HeatmapToken: os.Getenv("HEATMAP_TOKEN"),
+	}
+}
+
+func stringEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func durationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+func intEnv(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
+
+func floatEnv(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
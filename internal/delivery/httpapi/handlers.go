@@ -1,40 +1,103 @@
 package httpapi
 
 import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"image/png"
+	"io"
+	"io/fs"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
 	"calendar-wallpaper/internal/usecase"
 
 	"github.com/go-chi/chi/v5"
 )
 
+type EncodeObserver interface {
+	ObserveEncoded(device, format string, bytes int)
+}
+
 type Handler struct {
-	Service usecase.Service
+	Service        usecase.Service
+	Assets         fs.FS
+	Readiness      func() error
+	Metrics        EncodeObserver
+	MetricsHandler http.Handler
+	// HeatmapToken must be sent as a bearer token to upload heatmap data;
+	// uploads are disabled without one.
+	HeatmapToken string
+	// Version identifies the build; it is part of every wallpaper ETag.
+	Version string
+
+	RenderLimits []func(http.Handler) http.Handler
+
+	etagSalt string
 }
 
-func RegisterHandlers(router chi.Router, h Handler) {
+func RegisterHandlers(router chi.Router, h Handler) error {
+	images, err := fs.Sub(h.Assets, assets.ImagesPath)
+	if err != nil {
+		return err
+	}
+	if h.etagSalt, err = etagSalt(h.Version, h.Assets); err != nil {
+		return err
+	}
+
+	router.Get("/healthz", h.healthHandler)
+	router.Get("/readyz", h.readyHandler)
+	if h.MetricsHandler != nil {
+		router.Method(http.MethodGet, "/metrics", h.MetricsHandler)
+	}
 	router.Get("/", h.indexHandler)
-	router.Get("/wallpaper", h.wallpaperHandler)
+	router.With(h.RenderLimits...).Get("/wallpaper", h.wallpaperHandler)
+	router.With(h.RenderLimits...).Post("/heatmap/{name}", h.heatmapUploadHandler)
 	router.Handle("/images/*",
 		http.StripPrefix("/images/",
-			http.FileServer(http.Dir("web/images")),
+			http.FileServerFS(images),
 		),
 	)
+	return nil
 }
 
-func (h Handler) indexHandler(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, "web/index.html")
+func (h Handler) healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
 }
 
-func (h Handler) wallpaperHandler(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
+func (h Handler) readyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if h.Readiness != nil {
+		if err := h.Readiness(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	_, _ = w.Write([]byte("ready\n"))
+}
+
+func (h Handler) indexHandler(w http.ResponseWriter, r *http.Request) {
+	http.ServeFileFS(w, r, h.Assets, assets.IndexPath)
+}
 
+func parseRenderParams(q url.Values) usecase.RenderParams {
 	tz, _ := strconv.Atoi(q.Get("timezone"))
 	size, _ := strconv.Atoi(q.Get("size"))
+	width, _ := strconv.Atoi(q.Get("w"))
+	height, _ := strconv.Atoi(q.Get("h"))
+	inset, _ := strconv.Atoi(q.Get("inset"))
+	clockRatio, _ := strconv.ParseFloat(q.Get("clock_ratio"), 64)
+	buttonsRatio, _ := strconv.ParseFloat(q.Get("buttons_ratio"), 64)
 
-	params := usecase.RenderParams{
+	return usecase.RenderParams{
 		DeviceKey:   q.Get("device"),
 		Lang:        q.Get("lang"),
 		Weekends:    q.Get("weekends"),
@@ -43,17 +106,156 @@ func (h Handler) wallpaperHandler(w http.ResponseWriter, r *http.Request) {
 		SizePercent: size,
 		BgStyle:     q.Get("bg"),
 		BgColor:     q.Get("color"),
+		Widgets:     q.Get("widgets"),
+		Screen:      q.Get("screen"),
+		HomeStyle:   q.Get("home_style"),
+		Preview:     q.Get("preview") == "1",
+		WeekStart:   q.Get("week_start"),
+		WeekNumbers: q.Get("weeknums") == "1",
+		Weekdays:    q.Get("weekdays") == "1",
+		Mode:        q.Get("mode"),
+		Events:      q["event"],
+		Granularity: q.Get("granularity"),
+
+		Heatmap:      q.Get("heatmap"),
+		HeatmapColor: q.Get("heat_color"),
+
+		Font:         q.Get("font"),
+		FontWeight:   q.Get("font_weight"),
+		TitleFont:    q.Get("title_font"),
+		TitleWeight:  q.Get("title_weight"),
+		NumberFont:   q.Get("number_font"),
+		NumberWeight: q.Get("number_weight"),
+		FooterFont:   q.Get("footer_font"),
+		FooterWeight: q.Get("footer_weight"),
+
+		Footer:         q.Get("footer"),
+		FooterPosition: q.Get("footer_pos"),
+		FooterStyle:    q.Get("footer_style"),
+		Countdowns:     q["countdown"],
+
+		Width:        width,
+		Height:       height,
+		ClockRatio:   clockRatio,
+		ButtonsRatio: buttonsRatio,
+		Inset:        inset,
 	}
+}
 
-	img, err := h.Service.RenderWallpaper(params)
+func (h Handler) wallpaperHandler(w http.ResponseWriter, r *http.Request) {
+	params := parseRenderParams(r.URL.Query())
+
+	wallpaper, err := h.Service.PrepareWallpaper(r.Context(), params)
+	if errors.Is(err, domain.ErrInvalidDevice) || errors.Is(err, domain.ErrInvalidHeatmap) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	etag := wallpaper.ETag(h.etagSalt)
 
-	w.Header().Set("Content-Type", "image/png")
-	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	// Clients may keep the image but must revalidate it; the ETag changes
+	// with the wallpaper's granularity period.
+	w.Header().Set("Cache-Control", "no-cache, must-revalidate, max-age=0")
 	w.Header().Set("Pragma", "no-cache")
 	w.Header().Set("Expires", "0")
-	_ = png.Encode(w, img)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	img := wallpaper.Render(r.Context())
+
+	w.Header().Set("Content-Type", "image/png")
+
+	cw := &countingWriter{w: w}
+	endEncode := tracing.StartSpan(r.Context(), "encode")
+	_ = png.Encode(cw, img)
+	endEncode()
+	if h.Metrics != nil {
+		device, _ := params.Device()
+		h.Metrics.ObserveEncoded(device.Key, "png", cw.n)
+	}
+}
+
+// etagSalt hashes the build version and every font the renderer may
+// load, so a deploy that changes either never revalidates an image drawn
+// by the old one, while restarts and replicas of one build agree.
+func etagSalt(version string, fsys fs.FS) (string, error) {
+	h := fnv.New64a()
+	io.WriteString(h, version)
+	if fsys != nil {
+		err := fs.WalkDir(fsys, assets.FontDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "|%s|%d|", path, len(data))
+			h.Write(data)
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("hash fonts: %w", err)
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64()), nil
+}
+
+// etagMatches uses the weak comparison If-None-Match calls for, so W/
+// is ignored on both sides.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Uploads are a few years of daily values at most.
+const maxHeatmapUpload = 1 << 20
+
+// heatmapUploadHandler stores the JSON or CSV body as the named heatmap
+// data set; ?merge=1 keeps the days the body doesn't mention.
+func (h Handler) heatmapUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if h.HeatmapToken == "" {
+		http.Error(w, "heatmap uploads are disabled", http.StatusNotFound)
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.HeatmapToken)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHeatmapUpload))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	values, err := domain.ParseHeatmapValues(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.SaveHeatmap(r.Context(), chi.URLParam(r, "name"), values, r.URL.Query().Get("merge") == "1")
+	switch {
+	case errors.Is(err, domain.ErrInvalidHeatmap):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrHeatmapsDisabled):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
 }
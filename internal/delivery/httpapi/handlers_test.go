@@ -0,0 +1,372 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/heatmapstore"
+	"calendar-wallpaper/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type renderCall struct {
+	now      time.Time
+	device   domain.DeviceProfile
+	lang     string
+	weekends string
+	dayStyle domain.DayStyle
+	uiScale  float64
+	bgStyle  domain.BackgroundStyle
+}
+
+type recordingRenderer struct {
+	calls []renderCall
+}
+
+func (r *recordingRenderer) RenderCalendar(
+	ctx context.Context,
+	now time.Time,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	opts domain.RenderOptions,
+) *image.RGBA {
+	r.calls = append(r.calls, renderCall{now, device, opts.Lang, opts.Weekends, opts.DayStyle, opts.UIScale, opts.BgStyle})
+	return image.NewRGBA(image.Rect(0, 0, 1, 1))
+}
+
+func FuzzWallpaperQuery(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"device=iphone-15&lang=ru&weekends=red&style=bars&timezone=3&size=120&bg=noise&color=%23ff0000",
+		"timezone=-99999999999999999999&size=9223372036854775807",
+		"timezone=5.5&size=-1&device=../../etc",
+		"timezone=100&size=0",
+		"lang=%ZZ&color=%",
+		"w=1072&h=1448&clock_ratio=0.2&buttons_ratio=0.95&inset=0",
+		"w=100000&h=100000",
+		"w=1920&h=720&clock_ratio=NaN",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, rawQuery string) {
+		renderer := &recordingRenderer{}
+		h := Handler{Service: usecase.Service{
+			Clock:    usecase.FixedClock{Time: time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)},
+			Renderer: renderer,
+			Theme:    domain.IOSTheme(),
+		}}
+
+		r := httptest.NewRequest(http.MethodGet, "/wallpaper", nil)
+		r.URL.RawQuery = rawQuery
+		w := httptest.NewRecorder()
+		h.wallpaperHandler(w, r)
+
+		if w.Code == http.StatusBadRequest {
+			if len(renderer.calls) != 0 {
+				t.Fatalf("query %q: rendered despite bad request", rawQuery)
+			}
+			return
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("query %q: status %d", rawQuery, w.Code)
+		}
+		if len(renderer.calls) != 1 {
+			t.Fatalf("query %q: %d render calls", rawQuery, len(renderer.calls))
+		}
+
+		c := renderer.calls[0]
+		if _, offset := c.now.Zone(); offset < -12*3600 || offset > 14*3600 {
+			t.Fatalf("query %q: timezone offset %d out of range", rawQuery, offset)
+		}
+		if c.uiScale < 0.8 || c.uiScale > 1.3 {
+			t.Fatalf("query %q: ui scale %v out of range", rawQuery, c.uiScale)
+		}
+		if c.device.Key == domain.CustomDeviceKey {
+			if c.device.Width > domain.MaxCustomSide || c.device.Height > domain.MaxCustomSide ||
+				c.device.Width*c.device.Height > domain.MaxCustomPixels {
+				t.Fatalf("query %q: custom device %dx%d too large", rawQuery, c.device.Width, c.device.Height)
+			}
+			if c.device.ButtonsTop() <= c.device.ClockBottom() {
+				t.Fatalf("query %q: custom device has no room between zones", rawQuery)
+			}
+		} else if _, ok := domain.Devices().Lookup(c.device.Key); !ok {
+			t.Fatalf("query %q: unknown device %q", rawQuery, c.device.Key)
+		}
+		if c.lang != domain.NormalizeLang(c.lang) {
+			t.Fatalf("query %q: lang %q not normalized", rawQuery, c.lang)
+		}
+		switch c.weekends {
+		case "off", "gray", "green", "blue", "red":
+		default:
+			t.Fatalf("query %q: weekends %q not normalized", rawQuery, c.weekends)
+		}
+		if c.dayStyle != domain.ParseDayStyle(string(c.dayStyle)) {
+			t.Fatalf("query %q: day style %q not normalized", rawQuery, c.dayStyle)
+		}
+		if c.bgStyle != domain.ParseBackgroundStyle(string(c.bgStyle)) {
+			t.Fatalf("query %q: background %q not normalized", rawQuery, c.bgStyle)
+		}
+	})
+}
+
+func TestWallpaperETag(t *testing.T) {
+	clock := &steppingClock{t: time.Date(2026, 10, 21, 15, 10, 0, 0, time.UTC)}
+	renderer := &recordingRenderer{}
+	h := Handler{Service: usecase.Service{Clock: clock, Renderer: renderer, Theme: domain.IOSTheme()}}
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/wallpaper?"+query, nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.wallpaperHandler(w, r)
+		return w
+	}
+
+	first := get("mode=week", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first request: status %d, ETag %q", first.Code, etag)
+	}
+	for name, want := range map[string]string{
+		"Cache-Control": "no-cache, must-revalidate, max-age=0",
+		"Pragma":        "no-cache",
+		"Expires":       "0",
+	} {
+		if got := first.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	clock.t = clock.t.Add(30 * time.Minute)
+	if w := get("mode=week", etag); w.Code != http.StatusNotModified {
+		t.Errorf("same hour: status %d, want 304", w.Code)
+	}
+	if w := get("mode=week&style=bars", etag); w.Code != http.StatusOK {
+		t.Errorf("other params: status %d, want 200", w.Code)
+	}
+
+	clock.t = clock.t.Add(30 * time.Minute)
+	if w := get("mode=week", etag); w.Code != http.StatusOK {
+		t.Errorf("next hour: status %d, want 200", w.Code)
+	}
+	if w := get("mode=months", ""); get("mode=months", w.Header().Get("ETag")).Code != http.StatusNotModified {
+		t.Error("months view should keep its ETag within the day")
+	}
+	if len(renderer.calls) != 4 {
+		t.Errorf("%d renders, want 4", len(renderer.calls))
+	}
+
+	noise := get("bg=noise", "").Header().Get("ETag")
+	if !strings.HasPrefix(noise, `W/"`) {
+		t.Errorf("noise background ETag = %q, want a weak validator", noise)
+	}
+	if w := get("bg=noise", noise); w.Code != http.StatusNotModified {
+		t.Errorf("noise revalidation: status %d, want 304", w.Code)
+	}
+
+	months := get("mode=months", "").Header().Get("ETag")
+	h.etagSalt = "next-build"
+	if w := get("mode=months", months); w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("ETag"), `"`) {
+		t.Errorf("after a new build: status %d, ETag %q; want 200 with a new strong ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+type steppingClock struct{ t time.Time }
+
+func (c *steppingClock) Now() time.Time { return c.t }
+
+type countingStore struct {
+	*heatmapstore.Memory
+	loads atomic.Int32
+}
+
+func (s *countingStore) Load(ctx context.Context, name string) (domain.HeatmapValues, error) {
+	s.loads.Add(1)
+	return s.Memory.Load(ctx, name)
+}
+
+func TestWallpaperLoadsHeatmapOnce(t *testing.T) {
+	store := &countingStore{Memory: &heatmapstore.Memory{}}
+	store.Save(context.Background(), "runs", domain.HeatmapValues{{Year: 2026, Month: time.October, Day: 20}: 5})
+	h := Handler{Service: usecase.Service{
+		Clock:    usecase.FixedClock{Time: time.Date(2026, 10, 21, 15, 10, 0, 0, time.UTC)},
+		Renderer: &recordingRenderer{},
+		Theme:    domain.IOSTheme(),
+		Heatmaps: store,
+	}}
+
+	w := httptest.NewRecorder()
+	h.wallpaperHandler(w, httptest.NewRequest(http.MethodGet, "/wallpaper?mode=heatmap&heatmap=runs", nil))
+	if w.Code != http.StatusOK || store.loads.Load() != 1 {
+		t.Fatalf("status %d after %d loads, want 200 after 1", w.Code, store.loads.Load())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/wallpaper?mode=heatmap&heatmap=runs", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	h.wallpaperHandler(w, r)
+	if w.Code != http.StatusNotModified || store.loads.Load() != 2 {
+		t.Errorf("revalidation: status %d after %d loads, want 304 after 2", w.Code, store.loads.Load())
+	}
+}
+
+func TestETagSalt(t *testing.T) {
+	fonts := fstest.MapFS{
+		"fonts/a.ttf":          {Data: []byte("regular")},
+		"fonts/fallback/b.ttf": {Data: []byte("arabic")},
+		"web/index.html":       {Data: []byte("<html>")},
+	}
+	salt := func(version string, fsys fs.FS) string {
+		t.Helper()
+		p, err := etagSalt(version, fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	base := salt("v1+abc", fonts)
+	if again := salt("v1+abc", fonts); again != base {
+		t.Errorf("same build and fonts: %q then %q", base, again)
+	}
+	if salt("v1+abd", fonts) == base {
+		t.Error("salt ignores the version")
+	}
+
+	fonts["web/index.html"] = &fstest.MapFile{Data: []byte("<html lang=en>")}
+	if salt("v1+abc", fonts) != base {
+		t.Error("salt depends on files other than fonts")
+	}
+	fonts["fonts/fallback/b.ttf"] = &fstest.MapFile{Data: []byte("hebrew")}
+	if salt("v1+abc", fonts) == base {
+		t.Error("salt ignores a changed fallback font")
+	}
+}
+
+func TestHeatmapUpload(t *testing.T) {
+	store := &heatmapstore.Memory{}
+	h := Handler{
+		Service:      usecase.Service{Clock: usecase.FixedClock{}, Heatmaps: store},
+		HeatmapToken: "secret",
+	}
+	router := chi.NewRouter()
+	router.Post("/heatmap/{name}", h.heatmapUploadHandler)
+
+	post := func(path, token, body string) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		path, token, body string
+		want              int
+	}{
+		{"/heatmap/runs", "", "2026-10-19,5", http.StatusUnauthorized},
+		{"/heatmap/runs", "wrong", "2026-10-19,5", http.StatusUnauthorized},
+		{"/heatmap/runs", "secret", "2026-10-19,five", http.StatusBadRequest},
+		{"/heatmap/bad.name", "secret", "2026-10-19,5", http.StatusBadRequest},
+		{"/heatmap/runs", "secret", "date,km\n2026-10-19,5\n2026-10-20,3", http.StatusNoContent},
+		{"/heatmap/runs?merge=1", "secret", `{"2026-10-20": 8}`, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		if got := post(tt.path, tt.token, tt.body); got != tt.want {
+			t.Errorf("POST %s %q: status %d, want %d", tt.path, tt.body, got, tt.want)
+		}
+	}
+
+	values, err := store.Load(context.Background(), "runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mon := domain.Date{Year: 2026, Month: time.October, Day: 19}
+	tue := domain.Date{Year: 2026, Month: time.October, Day: 20}
+	if len(values) != 2 || values[mon] != 5 || values[tue] != 8 {
+		t.Errorf("stored %v, want 19th 5 and merged 20th 8", values)
+	}
+
+	if post("/heatmap/runs", "secret", `{"2026-10-21": 1}`) != http.StatusNoContent {
+		t.Fatal("replace failed")
+	}
+	if values, _ := store.Load(context.Background(), "runs"); len(values) != 1 {
+		t.Errorf("upload without merge kept %d days, want 1", len(values))
+	}
+
+	for name, off := range map[string]Handler{
+		"without a token": {Service: h.Service},
+		"without a store": {Service: usecase.Service{Clock: usecase.FixedClock{}}, HeatmapToken: "secret"},
+	} {
+		router = chi.NewRouter()
+		router.Post("/heatmap/{name}", off.heatmapUploadHandler)
+		if got := post("/heatmap/runs", "secret", "2026-10-19,5"); got != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", name, got)
+		}
+	}
+	if values, _ := store.Load(context.Background(), "runs"); len(values) != 1 {
+		t.Errorf("disabled upload changed the set to %v", values)
+	}
+}
+
+// slowStore widens the window between a merge's load and save.
+type slowStore struct{ *heatmapstore.Memory }
+
+func (s slowStore) Load(ctx context.Context, name string) (domain.HeatmapValues, error) {
+	values, err := s.Memory.Load(ctx, name)
+	time.Sleep(time.Millisecond)
+	return values, err
+}
+
+func TestHeatmapUploadConcurrentMerge(t *testing.T) {
+	store := &heatmapstore.Memory{}
+	h := Handler{
+		Service:      usecase.Service{Clock: usecase.FixedClock{}, Heatmaps: slowStore{store}},
+		HeatmapToken: "secret",
+	}
+	router := chi.NewRouter()
+	router.Post("/heatmap/{name}", h.heatmapUploadHandler)
+
+	const days = 28
+	var wg sync.WaitGroup
+	for day := 1; day <= days; day++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf("2026-02-%02d,%d", day, day)
+			r := httptest.NewRequest(http.MethodPost, "/heatmap/runs?merge=1", strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != http.StatusNoContent {
+				t.Errorf("day %d: status %d", day, w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	values, err := store.Load(context.Background(), "runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != days {
+		t.Errorf("merged %d days, want %d", len(values), days)
+	}
+}
@@ -0,0 +1,46 @@
+package httpapi
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"calendar-wallpaper/internal/ratelimit"
+)
+
+func RateLimit(l *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := l.Allow(clientIP(r))
+			if !ok {
+				setRetryAfter(w, wait)
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ConcurrencyLimit(s *ratelimit.Semaphore, queueTimeout, retryAfter time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.Acquire(r.Context(), queueTimeout) {
+				setRetryAfter(w, retryAfter)
+				http.Error(w, "server is busy", http.StatusServiceUnavailable)
+				return
+			}
+			defer s.Release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	secs := int(math.Ceil(d.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+}
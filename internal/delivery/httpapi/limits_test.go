@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/ratelimit"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRateLimitUsesRealIP(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	h := RateLimit(ratelimit.NewLimiter(0.5, 1, clock))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/wallpaper", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Real-IP", ip)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := request("1.1.1.1"); w.Code != http.StatusOK {
+		t.Fatalf("first request: status %d", w.Code)
+	}
+
+	w := request("1.1.1.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want 2", got)
+	}
+
+	if w := request("2.2.2.2"); w.Code != http.StatusOK {
+		t.Fatalf("other client: status %d", w.Code)
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+	if w := request("1.1.1.1"); w.Code != http.StatusOK {
+		t.Fatalf("after refill: status %d", w.Code)
+	}
+}
+
+func TestConcurrencyLimitRejectsWhenFull(t *testing.T) {
+	sem := ratelimit.NewSemaphore(1)
+	h := ConcurrencyLimit(sem, 0, 3*time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	sem.TryAcquire()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wallpaper", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Fatalf("Retry-After = %q, want 3", got)
+	}
+
+	sem.Release()
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wallpaper", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d after release", w.Code)
+	}
+	if sem.InUse() != 0 {
+		t.Fatal("slot was not released after request")
+	}
+}
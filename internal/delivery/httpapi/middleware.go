@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"calendar-wallpaper/internal/tracing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type RequestObserver interface {
+	ObserveRequest(route, method string, status int, d time.Duration)
+}
+
+func Instrument(obs RequestObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+
+			next.ServeHTTP(ww, r)
+
+			obs.ObserveRequest(routePattern(r), r.Method, ww.Status(), time.Since(start))
+		})
+	}
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+func AccessLog(logger *slog.Logger, exporter tracing.Exporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			trace := tracing.New(r.Method + " " + r.URL.Path)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(tracing.WithTrace(r.Context(), trace)))
+
+			trace.Finish()
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			trace.Annotate(
+				"http.method", r.Method,
+				"http.route", routePattern(r),
+				"http.status_code", strconv.Itoa(status),
+			)
+
+			attrs := []slog.Attr{
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("trace_id", trace.ID),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", routePattern(r)),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("duration", trace.Duration()),
+				slog.String("remote", clientIP(r)),
+			}
+			if params := traceParams(trace); len(params) > 0 {
+				attrs = append(attrs, slog.Group("params", params...))
+			}
+			if spans := traceSpans(trace); len(spans) > 0 {
+				attrs = append(attrs, slog.Group("spans", spans...))
+			}
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+
+			if exporter != nil {
+				exporter.Export(trace)
+			}
+		})
+	}
+}
+
+func traceParams(t *tracing.Trace) []any {
+	var out []any
+	for _, a := range t.Attrs() {
+		if strings.HasPrefix(a.Key, "http.") {
+			continue
+		}
+		out = append(out, slog.String(a.Key, a.Value))
+	}
+	return out
+}
+
+func traceSpans(t *tracing.Trace) []any {
+	var out []any
+	for _, s := range t.Spans() {
+		out = append(out, slog.Duration(s.Name, s.Duration()))
+	}
+	return out
+}
+
+func clientIP(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type countingWriter struct {
+	w http.ResponseWriter
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
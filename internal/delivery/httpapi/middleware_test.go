@@ -0,0 +1,147 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
+	"calendar-wallpaper/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type recordingExporter struct {
+	traces []*tracing.Trace
+}
+
+func (e *recordingExporter) Export(t *tracing.Trace) {
+	e.traces = append(e.traces, t)
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	exporter := &recordingExporter{}
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(AccessLog(logger, exporter))
+	router.Get("/heatmap/{name}", func(w http.ResponseWriter, r *http.Request) {
+		tracing.Annotate(r.Context(), "device", "iphone-15")
+		tracing.StartSpan(r.Context(), "encode")()
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/heatmap/runs?x=1", nil)
+	r.RemoteAddr = "192.0.2.7:51234"
+	r.Header.Set("X-Request-Id", "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry struct {
+		Msg       string           `json:"msg"`
+		RequestID string           `json:"request_id"`
+		TraceID   string           `json:"trace_id"`
+		Method    string           `json:"method"`
+		Path      string           `json:"path"`
+		Route     string           `json:"route"`
+		Status    int              `json:"status"`
+		Bytes     int              `json:"bytes"`
+		Duration  *int64           `json:"duration"`
+		Remote    string           `json:"remote"`
+		Params    map[string]any   `json:"params"`
+		Spans     map[string]int64 `json:"spans"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("%v: %s", err, logs.Bytes())
+	}
+
+	if entry.Msg != "request" || entry.RequestID != "req-1" || entry.Method != http.MethodGet ||
+		entry.Path != "/heatmap/runs" || entry.Route != "/heatmap/{name}" ||
+		entry.Status != http.StatusTeapot || entry.Bytes != 5 || entry.Remote != "192.0.2.7" || entry.Duration == nil {
+		t.Errorf("log entry = %+v", entry)
+	}
+	if len(entry.Params) != 1 || entry.Params["device"] != "iphone-15" {
+		t.Errorf("params = %v, want only device", entry.Params)
+	}
+	if _, ok := entry.Spans["encode"]; !ok || len(entry.Spans) != 1 {
+		t.Errorf("spans = %v, want encode", entry.Spans)
+	}
+
+	if len(exporter.traces) != 1 {
+		t.Fatalf("exported %d traces, want 1", len(exporter.traces))
+	}
+	trace := exporter.traces[0]
+	if trace.ID != entry.TraceID || trace.Name != "GET /heatmap/runs" {
+		t.Errorf("trace %s %q, log has trace_id %s", trace.ID, trace.Name, entry.TraceID)
+	}
+	want := map[string]string{"http.method": "GET", "http.route": "/heatmap/{name}", "http.status_code": "418"}
+	for _, a := range trace.Attrs() {
+		if v, ok := want[a.Key]; ok && v != a.Value {
+			t.Errorf("trace attr %s = %q, want %q", a.Key, a.Value, v)
+		}
+		delete(want, a.Key)
+	}
+	if len(want) > 0 {
+		t.Errorf("trace is missing %v", want)
+	}
+}
+
+func TestAccessLogNotModified(t *testing.T) {
+	var logs bytes.Buffer
+	h := Handler{Service: usecase.Service{
+		Clock:    usecase.FixedClock{Time: time.Date(2026, 10, 21, 15, 10, 0, 0, time.UTC)},
+		Renderer: &recordingRenderer{},
+		Theme:    domain.IOSTheme(),
+	}}
+	router := chi.NewRouter()
+	router.Use(AccessLog(slog.New(slog.NewJSONHandler(&logs, nil)), nil))
+	router.Get("/wallpaper", h.wallpaperHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wallpaper?device=iphone-xr", nil))
+	logs.Reset()
+
+	r := httptest.NewRequest(http.MethodGet, "/wallpaper?device=iphone-xr", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry struct {
+		Status int            `json:"status"`
+		Params map[string]any `json:"params"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != http.StatusNotModified || entry.Params["device"] != "iphone-xr" || entry.Params["mode"] == nil {
+		t.Errorf("304 log entry = %+v, want the render parameters", entry)
+	}
+}
+
+func TestAccessLogClientIP(t *testing.T) {
+	var logs bytes.Buffer
+	handler := AccessLog(slog.New(slog.NewJSONHandler(&logs, nil)), nil)(http.NotFoundHandler())
+
+	r := httptest.NewRequest(http.MethodGet, "/nope", nil)
+	r.Header.Set("X-Real-IP", "203.0.113.9")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry struct {
+		Remote string `json:"remote"`
+		Route  string `json:"route"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Remote != "203.0.113.9" || entry.Route != "unmatched" || entry.Status != http.StatusNotFound {
+		t.Errorf("log entry = %+v", entry)
+	}
+}
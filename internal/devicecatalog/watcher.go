@@ -0,0 +1,98 @@
+package devicecatalog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+func Load(path string) (*domain.Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read device catalog: %w", err)
+	}
+	c, err := domain.ParseCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Watcher reloads the catalog at Path when the file changes or a value
+// arrives on Reload. A broken file is logged and the previous catalog
+// stays active.
+type Watcher struct {
+	Path     string
+	Interval time.Duration
+	Reload   <-chan os.Signal
+	Logger   *slog.Logger
+	Apply    func(*domain.Catalog)
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stat(path string) (fileStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+func (w Watcher) Run(ctx context.Context) {
+	apply := w.Apply
+	if apply == nil {
+		apply = domain.SetDevices
+	}
+
+	last, _ := stat(w.Path)
+
+	var tick <-chan time.Time
+	if w.Interval > 0 {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.Reload:
+			last, _ = stat(w.Path)
+			w.reload(apply, "signal")
+		case <-tick:
+			cur, err := stat(w.Path)
+			if err != nil || cur == last {
+				continue
+			}
+			last = cur
+			w.reload(apply, "file change")
+		}
+	}
+}
+
+func (w Watcher) reload(apply func(*domain.Catalog), trigger string) {
+	c, err := Load(w.Path)
+	if err != nil {
+		w.Logger.Error("device catalog reload failed",
+			slog.String("path", w.Path),
+			slog.String("trigger", trigger),
+			slog.Any("error", err),
+		)
+		return
+	}
+	apply(c)
+	w.Logger.Info("device catalog reloaded",
+		slog.String("path", w.Path),
+		slog.String("trigger", trigger),
+		slog.Int("devices", c.Len()),
+	)
+}
@@ -0,0 +1,78 @@
+package devicecatalog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+const catalogJSON = `{"profiles": [{"key": "iphone-15", "name": "%s", "platform": "ios", "width": 1179, "height": 2556, "clock_zone_ratio": 0.31, "buttons_zone_ratio": 0.81}]}`
+
+func writeCatalog(t *testing.T, path, name string) {
+	t.Helper()
+	data := []byte(fmt.Sprintf(catalogJSON, name))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices.json")
+	writeCatalog(t, path, "first")
+
+	applied := make(chan *domain.Catalog, 4)
+	reload := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go Watcher{
+		Path:     path,
+		Interval: 10 * time.Millisecond,
+		Reload:   reload,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Apply:    func(c *domain.Catalog) { applied <- c },
+	}.Run(ctx)
+
+	name := func(c *domain.Catalog) string {
+		d, _ := c.Lookup("iphone-15")
+		return d.Name
+	}
+	wait := func() *domain.Catalog {
+		t.Helper()
+		select {
+		case c := <-applied:
+			return c
+		case <-time.After(2 * time.Second):
+			t.Fatal("catalog was not reloaded")
+			return nil
+		}
+	}
+
+	reload <- os.Interrupt
+	if got := name(wait()); got != "first" {
+		t.Fatalf("after signal: name = %q, want first", got)
+	}
+
+	// Size changes too, so coarse mtime resolution cannot hide the edit.
+	writeCatalog(t, path, "second edit")
+	if got := name(wait()); got != "second edit" {
+		t.Fatalf("after edit: name = %q, want %q", got, "second edit")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"profiles": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reload <- os.Interrupt
+	select {
+	case c := <-applied:
+		t.Fatalf("invalid catalog applied: %v", c.Keys())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
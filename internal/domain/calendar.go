@@ -1,33 +1,54 @@
 package domain
 
-import "time"
+import (
+	"time"
+)
 
 type MonthData struct {
-	Name         string
-	Days         int
-	PassedDays   int
-	IsCurrent    bool
+	Name       string
+	Year       int
+	Month      time.Month
+	Days       int
+	PassedDays int
+	IsCurrent  bool
+	// StartWeekday is the grid column of day 1, FirstWeekday its weekday.
 	StartWeekday int
+	FirstWeekday time.Weekday
+	// WeekNumbers holds the ISO week of each grid row, taken from the
+	// row's Monday.
+	WeekNumbers []int
+	// WeekdayInitials labels the grid columns.
+	WeekdayInitials [7]string
 }
 
-var monthNames = map[string][]string{
-	"en": {"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
-	"ru": {"Янв", "Фев", "Мар", "Апр", "Май", "Июн", "Июл", "Авг", "Сен", "Окт", "Ноя", "Дек"},
+// Weekday of the zero-based day. Negative days reach back into the first
+// row's leading blanks, so m.Weekday(col-m.StartWeekday) is the weekday of
+// grid column col.
+func (m MonthData) Weekday(day int) time.Weekday {
+	return time.Weekday(((int(m.FirstWeekday)+day)%7 + 7) % 7)
 }
 
-func NormalizeLang(lang string) string {
-	if _, ok := monthNames[lang]; ok {
-		return lang
+// HasEvent reports whether one of events falls on the zero-based day.
+func (m MonthData) HasEvent(day int, events []Event) bool {
+	for _, e := range events {
+		if e.On(m.Year, m.Month, day+1) {
+			return true
+		}
 	}
-	return "en"
+	return false
+}
+
+// TodayRow is the grid row holding today, or -1 outside the current month.
+func (m MonthData) TodayRow() int {
+	if !m.IsCurrent || m.PassedDays == 0 {
+		return -1
+	}
+	return (m.StartWeekday + m.PassedDays - 1) / 7
 }
 
 func Progress(t time.Time) (day, left, percent int) {
 	day = t.YearDay()
-	total := 365
-	if isLeap(t.Year()) {
-		total = 366
-	}
+	total := DaysInYear(t.Year())
 	left = total - day
 	percent = int(float64(day) / float64(total) * 100)
 	return
@@ -37,39 +58,59 @@ func isLeap(year int) bool {
 	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
 }
 
-func BuildMonths(now time.Time, lang string) []MonthData {
-	year := now.Year()
-	loc := now.Location()
-
-	names := monthNames[NormalizeLang(lang)]
+func BuildMonths(now time.Time, lang string, weekStart WeekStart) []MonthData {
 	months := make([]MonthData, 12)
+	for m := range months {
+		months[m] = BuildMonth(now, now.Year(), time.Month(m+1), lang, weekStart)
+	}
+	return months
+}
 
-	for m := 1; m <= 12; m++ {
-		first := time.Date(year, time.Month(m), 1, 0, 0, 0, 0, loc)
-		days := first.AddDate(0, 1, -1).Day()
+// BuildMonth lays out one month as of now; it need not be in now's year.
+func BuildMonth(now time.Time, year int, month time.Month, lang string, weekStart WeekStart) MonthData {
+	msgs := MessagesFor(lang)
 
-		weekday := (int(first.Weekday()) + 6) % 7
+	first := time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	days := first.AddDate(0, 1, -1).Day()
 
-		passed := 0
-		if int(now.Month()) > m {
-			passed = days
-		} else if int(now.Month()) == m {
-			passed = now.Day()
-		}
+	weekday := weekStart.Column(first.Weekday())
 
-		months[m-1] = MonthData{
-			Name:         names[m-1],
-			Days:         days,
-			PassedDays:   passed,
-			IsCurrent:    int(now.Month()) == m,
-			StartWeekday: weekday,
-		}
+	current := year == now.Year() && month == now.Month()
+	passed := 0
+	if current {
+		passed = now.Day()
+	} else if first.Before(now) {
+		passed = days
 	}
-	return months
+
+	return MonthData{
+		Name:         msgs.MonthsShort[month-1],
+		Year:         year,
+		Month:        month,
+		Days:         days,
+		PassedDays:   passed,
+		IsCurrent:    current,
+		StartWeekday: weekday,
+		FirstWeekday: first.Weekday(),
+		WeekNumbers:  weekNumbers(first, days, weekday, weekStart),
+
+		WeekdayInitials: msgs.WeekdayHeader(weekStart.Weekday()),
+	}
+}
+
+func weekNumbers(first time.Time, days, startCol int, weekStart WeekStart) []int {
+	rows := (startCol + days + 6) / 7
+	monday := weekStart.Column(time.Monday)
+
+	weeks := make([]int, rows)
+	for r := range weeks {
+		_, weeks[r] = first.AddDate(0, 0, r*7+monday-startCol).ISOWeek()
+	}
+	return weeks
 }
 
 func DaysInYear(year int) int {
-	if time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+	if isLeap(year) {
 		return 366
 	}
 	return 365
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsLeap(t *testing.T) {
+	tests := []struct {
+		year int
+		want bool
+	}{
+		{1900, false},
+		{2000, true},
+		{2023, false},
+		{2024, true},
+		{2100, false},
+		{2400, true},
+		{-4, true},
+		{0, true},
+	}
+	for _, tt := range tests {
+		if got := isLeap(tt.year); got != tt.want {
+			t.Errorf("isLeap(%d) = %v, want %v", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestDaysInYearMatchesCalendar(t *testing.T) {
+	for year := 1600; year <= 2600; year++ {
+		want := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		if got := DaysInYear(year); got != want {
+			t.Fatalf("DaysInYear(%d) = %d, want %d", year, got, want)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		date                      time.Time
+		wantDay, wantLeft, wantPc int
+	}{
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 1, 364, 0},
+		{time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC), 365, 0, 100},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), 366, 0, 100},
+		{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), 60, 306, 16},
+		{time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC), 183, 182, 50},
+	}
+	for _, tt := range tests {
+		day, left, percent := Progress(tt.date)
+		if day != tt.wantDay || left != tt.wantLeft || percent != tt.wantPc {
+			t.Errorf("Progress(%s) = (%d, %d, %d), want (%d, %d, %d)",
+				tt.date.Format("2006-01-02"), day, left, percent, tt.wantDay, tt.wantLeft, tt.wantPc)
+		}
+	}
+}
+
+func TestBuildMonthsProperties(t *testing.T) {
+	start := time.Date(2023, time.December, 30, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	for instant := start; instant.Before(end); instant = instant.Add(7 * time.Hour) {
+		for tz := -12; tz <= 14; tz++ {
+			now := instant.In(time.FixedZone("tz", tz*3600))
+			for _, ws := range []WeekStart{WeekMonday, WeekSunday, WeekSaturday} {
+				checkMonths(t, now, ws, BuildMonths(now, "en", ws))
+			}
+		}
+	}
+}
+
+func checkMonths(t *testing.T, now time.Time, ws WeekStart, months []MonthData) {
+	t.Helper()
+
+	if len(months) != 12 {
+		t.Fatalf("%s: got %d months", now, len(months))
+	}
+
+	totalDays, passed, current := 0, 0, 0
+	for i, m := range months {
+		first := time.Date(now.Year(), time.Month(i+1), 1, 0, 0, 0, 0, time.UTC)
+		if m.Days != first.AddDate(0, 1, -1).Day() {
+			t.Fatalf("%s: month %d has %d days", now, i+1, m.Days)
+		}
+		if want := (int(first.Weekday()) - int(ws.Weekday()) + 7) % 7; m.StartWeekday != want {
+			t.Fatalf("%s: month %d starts on %d, want %d", now, i+1, m.StartWeekday, want)
+		}
+		if rows := (m.StartWeekday + m.Days + 6) / 7; len(m.WeekNumbers) != rows {
+			t.Fatalf("%s: month %d has %d week numbers for %d rows", now, i+1, len(m.WeekNumbers), rows)
+		}
+		if m.PassedDays < 0 || m.PassedDays > m.Days {
+			t.Fatalf("%s: month %d passed %d of %d", now, i+1, m.PassedDays, m.Days)
+		}
+		if m.IsCurrent {
+			current++
+			if m.PassedDays != now.Day() {
+				t.Fatalf("%s: current month passed %d, want %d", now, m.PassedDays, now.Day())
+			}
+		}
+		totalDays += m.Days
+		passed += m.PassedDays
+	}
+
+	if current != 1 {
+		t.Fatalf("%s: %d current months", now, current)
+	}
+	if totalDays != DaysInYear(now.Year()) {
+		t.Fatalf("%s: months sum to %d days", now, totalDays)
+	}
+	if day, _, _ := Progress(now); passed != day {
+		t.Fatalf("%s: passed days %d disagree with Progress day %d", now, passed, day)
+	}
+}
+
+func TestBuildMonthsLanguageFallback(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got := BuildMonths(now, "xx", WeekMonday)[0].Name; got != "Jan" {
+		t.Fatalf("unknown language month name = %q, want Jan", got)
+	}
+	if got := BuildMonths(now, "ru", WeekMonday)[0].Name; got != "Янв" {
+		t.Fatalf("ru month name = %q, want Янв", got)
+	}
+}
+
+func TestWeekNumbers(t *testing.T) {
+	// 2026-01-01 is a Thursday in ISO week 1; 2027-01-01 is a Friday in
+	// week 53 of 2026.
+	tests := []struct {
+		year      int
+		month     time.Month
+		weekStart WeekStart
+		want      []int
+	}{
+		{2026, time.January, WeekMonday, []int{1, 2, 3, 4, 5}},
+		{2026, time.January, WeekSunday, []int{1, 2, 3, 4, 5}},
+		{2027, time.January, WeekMonday, []int{53, 1, 2, 3, 4}},
+		{2026, time.March, WeekMonday, []int{9, 10, 11, 12, 13, 14}},
+		{2026, time.March, WeekSunday, []int{10, 11, 12, 13, 14}},
+		{2026, time.March, WeekSaturday, []int{10, 11, 12, 13, 14}},
+	}
+	for _, tt := range tests {
+		now := time.Date(tt.year, time.June, 1, 0, 0, 0, 0, time.UTC)
+		m := BuildMonths(now, "en", tt.weekStart)[tt.month-1]
+		if !slices.Equal(m.WeekNumbers, tt.want) {
+			t.Errorf("%d-%02d %s: weeks %v, want %v", tt.year, tt.month, tt.weekStart, m.WeekNumbers, tt.want)
+		}
+	}
+}
+
+func TestTodayRowAndWeekday(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	oct := BuildMonths(now, "en", WeekMonday)[9]
+	if got := oct.TodayRow(); got != 3 {
+		t.Errorf("TodayRow = %d, want 3", got)
+	}
+	if got := oct.Weekday(18); got != time.Monday {
+		t.Errorf("Weekday(18) = %s, want Monday", got)
+	}
+	if got := BuildMonths(now, "en", WeekMonday)[8].TodayRow(); got != -1 {
+		t.Errorf("TodayRow outside the current month = %d, want -1", got)
+	}
+}
+
+func TestWeekdayInitials(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		lang  string
+		start WeekStart
+		want  [7]string
+	}{
+		{"en", WeekMonday, [7]string{"M", "T", "W", "T", "F", "S", "S"}},
+		{"en", WeekSunday, [7]string{"S", "M", "T", "W", "T", "F", "S"}},
+		{"ru", WeekMonday, [7]string{"П", "В", "С", "Ч", "П", "С", "В"}},
+		{"ar", WeekSaturday, [7]string{"س", "ح", "ن", "ث", "ر", "خ", "ج"}},
+	}
+	for _, tt := range tests {
+		m := BuildMonths(now, tt.lang, tt.start)[9]
+		if m.WeekdayInitials != tt.want {
+			t.Errorf("%s %s: initials = %q, want %q", tt.lang, tt.start, m.WeekdayInitials, tt.want)
+		}
+		// The blank columns before day 1 still map to their weekdays.
+		for col := range 7 {
+			if got, want := m.Weekday(col-m.StartWeekday), (tt.start.Weekday()+time.Weekday(col))%7; got != want {
+				t.Errorf("%s: column %d weekday = %s, want %s", tt.start, col, got, want)
+			}
+		}
+	}
+}
+
+func TestBuildMonthOutsideYear(t *testing.T) {
+	now := time.Date(2027, time.January, 7, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		year    int
+		month   time.Month
+		passed  int
+		current bool
+	}{
+		{2026, time.December, 31, false},
+		{2027, time.January, 7, true},
+		{2027, time.February, 0, false},
+	}
+	for _, tt := range tests {
+		m := BuildMonth(now, tt.year, tt.month, "en", WeekMonday)
+		if m.PassedDays != tt.passed || m.IsCurrent != tt.current {
+			t.Errorf("%d-%02d: passed %d current %v, want %d %v",
+				tt.year, tt.month, m.PassedDays, m.IsCurrent, tt.passed, tt.current)
+		}
+	}
+}
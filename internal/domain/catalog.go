@@ -0,0 +1,167 @@
+package domain
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultDeviceKey is used for unknown keys, so every catalog must have it.
+const DefaultDeviceKey = "iphone-15"
+
+//go:embed devices.json
+var embeddedCatalog []byte
+
+// Catalog is an immutable set of device profiles. Aliases resolve to a copy
+// of their profile carrying the alias as its Key.
+type Catalog struct {
+	devices  map[string]DeviceProfile
+	keys     []string
+	profiles []string
+}
+
+type catalogFile struct {
+	Profiles []catalogEntry `json:"profiles"`
+}
+
+type catalogEntry struct {
+	DeviceProfile
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+var deviceKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var f catalogFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse device catalog: %w", err)
+	}
+
+	c := &Catalog{devices: make(map[string]DeviceProfile)}
+	var errs []error
+	add := func(key string, d DeviceProfile) bool {
+		if !deviceKeyPattern.MatchString(key) {
+			errs = append(errs, fmt.Errorf("invalid device key %q", key))
+			return false
+		}
+		if _, dup := c.devices[key]; dup {
+			errs = append(errs, fmt.Errorf("duplicate device key %q", key))
+			return false
+		}
+		d.Key = key
+		c.devices[key] = d
+		c.keys = append(c.keys, key)
+		return true
+	}
+
+	for _, e := range f.Profiles {
+		if err := validateProfile(e.DeviceProfile); err != nil {
+			errs = append(errs, fmt.Errorf("device %q: %w", e.Key, err))
+			continue
+		}
+		if add(e.Key, e.DeviceProfile) {
+			c.profiles = append(c.profiles, e.Key)
+		}
+		for _, alias := range e.Aliases {
+			add(alias, e.DeviceProfile)
+		}
+	}
+	if _, ok := c.devices[DefaultDeviceKey]; !ok && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("default device %q is missing", DefaultDeviceKey))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	sort.Strings(c.keys)
+	sort.Strings(c.profiles)
+	return c, nil
+}
+
+func validateProfile(d DeviceProfile) error {
+	var errs []error
+	if d.Name == "" {
+		errs = append(errs, errors.New("name is empty"))
+	}
+	switch d.Platform {
+	case PlatformIOS, PlatformAndroid, PlatformIPadOS, PlatformMacOS:
+	default:
+		errs = append(errs, fmt.Errorf("unknown platform %q", d.Platform))
+	}
+	if d.Width < MinCustomSide || d.Width > MaxCustomSide || d.Height < MinCustomSide || d.Height > MaxCustomSide {
+		errs = append(errs, fmt.Errorf("size %dx%d outside %d..%d", d.Width, d.Height, MinCustomSide, MaxCustomSide))
+	} else if d.Width*d.Height > MaxCustomPixels {
+		errs = append(errs, fmt.Errorf("size %dx%d exceeds %d pixels", d.Width, d.Height, MaxCustomPixels))
+	}
+	if d.LogicalWidth < 0 || d.LogicalWidth > d.Width {
+		errs = append(errs, fmt.Errorf("logical_width %d outside 0..%d", d.LogicalWidth, d.Width))
+	}
+	if d.BottomInset < 0 {
+		errs = append(errs, fmt.Errorf("bottom_inset %d is negative", d.BottomInset))
+	}
+
+	for name, r := range map[string]float64{
+		"clock_zone_ratio":    d.ClockZoneRatio,
+		"buttons_zone_ratio":  d.ButtonsZoneRatio,
+		"inline_widget_ratio": d.InlineWidgetRatio,
+		"widget_row_ratio":    d.WidgetRowRatio,
+		"status_bar_ratio":    d.StatusBarRatio,
+		"icon_grid_top_ratio": d.IconGridTopRatio,
+		"dock_top_ratio":      d.DockTopRatio,
+	} {
+		if !(r >= 0 && r <= 1) {
+			errs = append(errs, fmt.Errorf("%s %v outside 0..1", name, r))
+		}
+	}
+	if d.ButtonsZoneRatio <= d.ClockZoneRatio {
+		errs = append(errs, fmt.Errorf("buttons_zone_ratio %v not below clock_zone_ratio %v", d.ButtonsZoneRatio, d.ClockZoneRatio))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Catalog) Lookup(key string) (DeviceProfile, bool) {
+	d, ok := c.devices[key]
+	return d, ok
+}
+
+// Keys returns every device key including aliases, sorted.
+func (c *Catalog) Keys() []string {
+	return append([]string(nil), c.keys...)
+}
+
+// ProfileKeys returns the key of each profile, without aliases, sorted.
+func (c *Catalog) ProfileKeys() []string {
+	return append([]string(nil), c.profiles...)
+}
+
+func (c *Catalog) Len() int {
+	return len(c.keys)
+}
+
+func DefaultCatalog() *Catalog {
+	c, err := ParseCatalog(embeddedCatalog)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+var devices atomic.Pointer[Catalog]
+
+func init() {
+	devices.Store(DefaultCatalog())
+}
+
+// Devices returns the active catalog. It can be swapped at runtime with
+// SetDevices, so callers should not hold on to it across requests.
+func Devices() *Catalog {
+	return devices.Load()
+}
+
+func SetDevices(c *Catalog) {
+	devices.Store(c)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalog(t *testing.T) {
+	c := DefaultCatalog()
+	if _, ok := c.Lookup(DefaultDeviceKey); !ok {
+		t.Fatalf("default device %q missing", DefaultDeviceKey)
+	}
+
+	alias, ok := c.Lookup("iphone-13")
+	if !ok {
+		t.Fatal("alias iphone-13 missing")
+	}
+	target, _ := c.Lookup("iphone-12")
+	if alias.Key != "iphone-13" {
+		t.Errorf("alias key = %q, want iphone-13", alias.Key)
+	}
+	alias.Key = target.Key
+	if alias != target {
+		t.Errorf("alias iphone-13 = %+v, want geometry of iphone-12 %+v", alias, target)
+	}
+
+	// The 16e has a notch, so the preview must not draw an island on it.
+	if d, ok := c.Lookup("iphone-16e"); !ok || d.DynamicIsland {
+		t.Errorf("Lookup(iphone-16e) = %+v, %v; want a profile without Dynamic Island", d, ok)
+	}
+
+	for _, key := range c.Keys() {
+		d, _ := c.Lookup(key)
+		if d.Key != key {
+			t.Errorf("Lookup(%q).Key = %q", key, d.Key)
+		}
+	}
+
+	profiles := c.ProfileKeys()
+	if !slices.Contains(profiles, "iphone-12") || slices.Contains(profiles, "iphone-13") {
+		t.Errorf("ProfileKeys() = %v, want iphone-12 without its alias iphone-13", profiles)
+	}
+	if len(profiles) >= len(c.Keys()) || !slices.IsSorted(profiles) {
+		t.Errorf("ProfileKeys() = %v", profiles)
+	}
+}
+
+const validProfile = `{"key": "iphone-15", "name": "iPhone 15", "platform": "ios", "width": 1179, "height": 2556, "clock_zone_ratio": 0.31, "buttons_zone_ratio": 0.81`
+
+func TestParseCatalogErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"malformed", `{"profiles": [`, "parse device catalog"},
+		{"duplicate key", `{"profiles": [` + validProfile + `}, ` + validProfile + `}]}`, `duplicate device key "iphone-15"`},
+		{"alias clashes with key", `{"profiles": [` + validProfile + `, "aliases": ["iphone-15"]}]}`, `duplicate device key "iphone-15"`},
+		{"bad key", `{"profiles": [` + validProfile + `, "aliases": ["iPhone 15"]}]}`, `invalid device key "iPhone 15"`},
+		{"ratio out of range", `{"profiles": [` + validProfile + `, "dock_top_ratio": 1.5}]}`, "dock_top_ratio 1.5 outside 0..1"},
+		{"zones overlap", `{"profiles": [{"key": "iphone-15", "name": "x", "platform": "ios", "width": 100, "height": 200, "clock_zone_ratio": 0.8, "buttons_zone_ratio": 0.5}]}`, "not below clock_zone_ratio"},
+		{"unknown platform", `{"profiles": [{"key": "iphone-15", "name": "x", "platform": "symbian", "width": 100, "height": 200, "clock_zone_ratio": 0.3, "buttons_zone_ratio": 0.8}]}`, `unknown platform "symbian"`},
+		{"huge", `{"profiles": [{"key": "iphone-15", "name": "x", "platform": "ios", "width": 100000, "height": 200, "clock_zone_ratio": 0.3, "buttons_zone_ratio": 0.8}]}`, "outside 64..8192"},
+		{"missing default", `{"profiles": [{"key": "pixel-9", "name": "x", "platform": "android", "width": 1080, "height": 2424, "clock_zone_ratio": 0.3, "buttons_zone_ratio": 0.8}]}`, "default device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCatalog([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const CustomDeviceKey = "custom"
+
+// Limits for ad-hoc devices. The pixel cap keeps a single RGBA canvas
+// under 64 MiB; the largest catalog entry (5K display) is 14.7 MP.
+const (
+	MinCustomSide   = 64
+	MaxCustomSide   = 8192
+	MaxCustomPixels = 16 << 20
+
+	defaultCustomClockRatio   = 0.30
+	defaultCustomButtonsRatio = 0.88
+)
+
+var ErrInvalidDevice = errors.New("invalid device")
+
+type CustomDeviceSpec struct {
+	Width        int
+	Height       int
+	ClockRatio   float64
+	ButtonsRatio float64
+	InsetPx      int
+}
+
+// CustomDevice builds a profile for a screen that is not in the catalog.
+// Zero ratios fall back to typical phone values.
+func CustomDevice(spec CustomDeviceSpec) (DeviceProfile, error) {
+	w, h := spec.Width, spec.Height
+	if w < MinCustomSide || w > MaxCustomSide || h < MinCustomSide || h > MaxCustomSide {
+		return DeviceProfile{}, fmt.Errorf("%w: size %dx%d outside %d..%d", ErrInvalidDevice, w, h, MinCustomSide, MaxCustomSide)
+	}
+	if w*h > MaxCustomPixels {
+		return DeviceProfile{}, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrInvalidDevice, w, h, MaxCustomPixels)
+	}
+
+	clockRatio := spec.ClockRatio
+	if clockRatio == 0 {
+		clockRatio = defaultCustomClockRatio
+	}
+	buttonsRatio := spec.ButtonsRatio
+	if buttonsRatio == 0 {
+		buttonsRatio = defaultCustomButtonsRatio
+	}
+	// Written as negated ranges so NaN is rejected too.
+	if !(clockRatio >= 0 && clockRatio < 0.6) {
+		return DeviceProfile{}, fmt.Errorf("%w: clock_ratio %v outside 0..0.6", ErrInvalidDevice, spec.ClockRatio)
+	}
+	if !(buttonsRatio > 0.4 && buttonsRatio <= 1) {
+		return DeviceProfile{}, fmt.Errorf("%w: buttons_ratio %v outside 0.4..1", ErrInvalidDevice, spec.ButtonsRatio)
+	}
+	if buttonsRatio-clockRatio < 0.2 {
+		return DeviceProfile{}, fmt.Errorf("%w: less than 20%% of the height left between clock and buttons", ErrInvalidDevice)
+	}
+	if spec.InsetPx < 0 || spec.InsetPx > h/4 {
+		return DeviceProfile{}, fmt.Errorf("%w: inset %d outside 0..%d", ErrInvalidDevice, spec.InsetPx, h/4)
+	}
+
+	// Point geometry follows the short side so preview controls keep
+	// phone proportions on landscape screens.
+	short := min(w, h)
+	d := DeviceProfile{
+		Key:              CustomDeviceKey,
+		Name:             fmt.Sprintf("Custom %dx%d", w, h),
+		Platform:         PlatformCustom,
+		Width:            w,
+		Height:           h,
+		LogicalWidth:     w * pointsPerBaseWidth / short,
+		ClockZoneRatio:   clockRatio,
+		ButtonsZoneRatio: buttonsRatio,
+	}
+	d.BottomInset = int(math.Round(float64(spec.InsetPx) / d.PointScale()))
+	return d, nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCustomDevice(t *testing.T) {
+	tests := []struct {
+		name string
+		spec CustomDeviceSpec
+		ok   bool
+	}{
+		{"e-ink reader", CustomDeviceSpec{Width: 1072, Height: 1448, ClockRatio: 0.1, ButtonsRatio: 0.95}, true},
+		{"car display", CustomDeviceSpec{Width: 1920, Height: 720}, true},
+		{"defaults with inset", CustomDeviceSpec{Width: 1179, Height: 2556, InsetPx: 100}, true},
+		{"largest square", CustomDeviceSpec{Width: 4096, Height: 4096}, true},
+		{"zero height", CustomDeviceSpec{Width: 1179}, false},
+		{"too small", CustomDeviceSpec{Width: 32, Height: 32}, false},
+		{"side too large", CustomDeviceSpec{Width: 100000, Height: 100}, false},
+		{"too many pixels", CustomDeviceSpec{Width: 8192, Height: 8192}, false},
+		{"negative size", CustomDeviceSpec{Width: -1179, Height: -2556}, false},
+		{"clock ratio NaN", CustomDeviceSpec{Width: 1179, Height: 2556, ClockRatio: math.NaN()}, false},
+		{"buttons ratio above 1", CustomDeviceSpec{Width: 1179, Height: 2556, ButtonsRatio: 1.5}, false},
+		{"zones overlap", CustomDeviceSpec{Width: 1179, Height: 2556, ClockRatio: 0.5, ButtonsRatio: 0.6}, false},
+		{"negative inset", CustomDeviceSpec{Width: 1179, Height: 2556, InsetPx: -1}, false},
+		{"inset too large", CustomDeviceSpec{Width: 1179, Height: 2556, InsetPx: 2000}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := CustomDevice(tt.spec)
+			if !tt.ok {
+				if !errors.Is(err, ErrInvalidDevice) {
+					t.Fatalf("err = %v, want ErrInvalidDevice", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.Width != tt.spec.Width || d.Height != tt.spec.Height {
+				t.Fatalf("size = %dx%d, want %dx%d", d.Width, d.Height, tt.spec.Width, tt.spec.Height)
+			}
+			if d.ButtonsTop() <= d.ClockBottom() {
+				t.Fatalf("buttons top %d not below clock bottom %d", d.ButtonsTop(), d.ClockBottom())
+			}
+			if got := d.BottomInsetPx(); abs(got-tt.spec.InsetPx) > 1 {
+				t.Fatalf("inset = %dpx, want %dpx", got, tt.spec.InsetPx)
+			}
+		})
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
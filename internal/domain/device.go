@@ -1,168 +1,88 @@
 package domain
 
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformIPadOS  Platform = "ipados"
+	PlatformMacOS   Platform = "macos"
+	PlatformCustom  Platform = "custom"
+)
+
 type DeviceProfile struct {
-	Key    string
-	Name   string
-	Width  int
-	Height int
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Platform Platform `json:"platform"`
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+
+	// LogicalWidth is the screen width in points (iOS) or dp (Android);
+	// zero means the 393pt base iPhone.
+	LogicalWidth int `json:"logical_width,omitempty"`
 
-	ClockZoneRatio   float64
-	ButtonsZoneRatio float64
+	ClockZoneRatio   float64 `json:"clock_zone_ratio"`
+	ButtonsZoneRatio float64 `json:"buttons_zone_ratio"`
 
-	BottomInset int
+	InlineWidgetRatio float64 `json:"inline_widget_ratio,omitempty"`
+	WidgetRowRatio    float64 `json:"widget_row_ratio,omitempty"`
+
+	StatusBarRatio   float64 `json:"status_bar_ratio,omitempty"`
+	IconGridTopRatio float64 `json:"icon_grid_top_ratio,omitempty"`
+	DockTopRatio     float64 `json:"dock_top_ratio,omitempty"`
+
+	BottomInset   int  `json:"bottom_inset,omitempty"`
+	DynamicIsland bool `json:"dynamic_island,omitempty"`
+	PunchHole     bool `json:"punch_hole,omitempty"`
 }
 
-func (d DeviceProfile) ClockBottom() int {
-	return int(float64(d.Height) * d.ClockZoneRatio)
+func (d DeviceProfile) IsPhone() bool {
+	return d.Platform == PlatformIOS || d.Platform == PlatformAndroid
 }
 
-func (d DeviceProfile) ButtonsTop() int {
-	return int(float64(d.Height) * d.ButtonsZoneRatio)
+func (d DeviceProfile) IsLandscape() bool {
+	return d.Width > d.Height
 }
 
-var (
-	profileSE23 = DeviceProfile{
-		Name:             "iPhone SE (2 / 3)",
-		Width:            750,
-		Height:           1334,
-		ClockZoneRatio:   0.26,
-		ButtonsZoneRatio: 0.84,
-	}
+func (d DeviceProfile) ClockBottom() int {
+	return int(float64(d.Height) * d.ClockZoneRatio)
+}
 
-	profile12 = DeviceProfile{
-		Name:             "iPhone 12 / 13 / 14",
-		Width:            1170,
-		Height:           2532,
-		ClockZoneRatio:   0.30,
-		ButtonsZoneRatio: 0.82,
-		BottomInset:      34,
+func (d DeviceProfile) WidgetBand(layout WidgetLayout) int {
+	var ratio float64
+	switch layout {
+	case WidgetsInline:
+		ratio = d.InlineWidgetRatio
+		if ratio == 0 {
+			ratio = defaultInlineWidgetRatio
+		}
+	case WidgetsRow:
+		ratio = d.WidgetRowRatio
+		if ratio == 0 {
+			ratio = defaultWidgetRowRatio
+		}
 	}
+	return int(float64(d.Height) * ratio)
+}
 
-	profile15 = DeviceProfile{
-		Name:             "iPhone 15 / 16",
-		Width:            1179,
-		Height:           2556,
-		ClockZoneRatio:   0.31,
-		ButtonsZoneRatio: 0.81,
-		BottomInset:      34,
-	}
+func (d DeviceProfile) GridTop(layout WidgetLayout) int {
+	return d.ClockBottom() + d.WidgetBand(layout)
+}
 
-	profilePro = DeviceProfile{
-		Name:             "iPhone Pro (Dynamic Island)",
-		Width:            1179,
-		Height:           2556,
-		ClockZoneRatio:   0.32,
-		ButtonsZoneRatio: 0.80,
-		BottomInset:      34,
-	}
+func (d DeviceProfile) ButtonsTop() int {
+	return int(float64(d.Height) * d.ButtonsZoneRatio)
+}
 
-	profileProMax = DeviceProfile{
-		Name:             "iPhone Pro Max",
-		Width:            1290,
-		Height:           2796,
-		ClockZoneRatio:   0.32,
-		ButtonsZoneRatio: 0.80,
-		BottomInset:      34,
-	}
-)
+const pointsPerBaseWidth = 393
 
-var Devices = map[string]DeviceProfile{
-
-	"iphone-se-1": {
-		Key: "iphone-se-1", Name: "iPhone SE (1st gen)",
-		Width: 640, Height: 1136,
-		ClockZoneRatio: 0.25, ButtonsZoneRatio: 0.84,
-	},
-	"iphone-se-2": withKey("iphone-se-2", profileSE23),
-	"iphone-se-3": withKey("iphone-se-3", profileSE23),
-	"iphone-16e":  withKey("iphone-16e", profile15),
-
-	"iphone-x": {
-		Key: "iphone-x", Name: "iPhone X / XS / 11 Pro",
-		Width: 1125, Height: 2436,
-		ClockZoneRatio: 0.30, ButtonsZoneRatio: 0.82,
-		BottomInset: 34,
-	},
-	"iphone-xr": {
-		Key: "iphone-xr", Name: "iPhone XR / 11",
-		Width: 828, Height: 1792,
-		ClockZoneRatio: 0.29, ButtonsZoneRatio: 0.83,
-		BottomInset: 34,
-	},
-	"iphone-xs-max": {
-		Key: "iphone-xs-max", Name: "iPhone XS Max / 11 Pro Max",
-		Width: 1242, Height: 2688,
-		ClockZoneRatio: 0.30, ButtonsZoneRatio: 0.82,
-		BottomInset: 34,
-	},
-
-	"iphone-12-mini": {
-		Key: "iphone-12-mini", Name: "iPhone 12 mini",
-		Width: 1080, Height: 2340,
-		ClockZoneRatio: 0.30, ButtonsZoneRatio: 0.82,
-		BottomInset: 34,
-	},
-	"iphone-13-mini": {
-		Key: "iphone-13-mini", Name: "iPhone 13 mini",
-		Width: 1080, Height: 2340,
-		ClockZoneRatio: 0.30, ButtonsZoneRatio: 0.82,
-		BottomInset: 34,
-	},
-
-	"iphone-12": withKey("iphone-12", profile12),
-	"iphone-13": withKey("iphone-13", profile12),
-	"iphone-14": withKey("iphone-14", profile12),
-	"iphone-15": withKey("iphone-15", profile15),
-	"iphone-16": withKey("iphone-16", profile15),
-	"iphone-17": withKey("iphone-17", profile15),
-
-	"iphone-14-plus": {
-		Key: "iphone-14-plus", Name: "iPhone 14 Plus",
-		Width: 1284, Height: 2778,
-		ClockZoneRatio: 0.31, ButtonsZoneRatio: 0.81,
-		BottomInset: 34,
-	},
-	"iphone-15-plus": {
-		Key: "iphone-15-plus", Name: "iPhone 15 Plus",
-		Width: 1290, Height: 2796,
-		ClockZoneRatio: 0.31, ButtonsZoneRatio: 0.81,
-		BottomInset: 34,
-	},
-	"iphone-16-plus": {
-		Key: "iphone-16-plus", Name: "iPhone 16 Plus",
-		Width: 1290, Height: 2796,
-		ClockZoneRatio: 0.31, ButtonsZoneRatio: 0.81,
-		BottomInset: 34,
-	},
-
-	"iphone-12-pro": withKey("iphone-12-pro", profile12),
-	"iphone-13-pro": withKey("iphone-13-pro", profile12),
-	"iphone-14-pro": withKey("iphone-14-pro", profilePro),
-	"iphone-15-pro": withKey("iphone-15-pro", profilePro),
-	"iphone-16-pro": {
-		Key: "iphone-16-pro", Name: "iPhone 16 Pro",
-		Width: 1206, Height: 2622,
-		ClockZoneRatio: 0.32, ButtonsZoneRatio: 0.80,
-		BottomInset: 34,
-	},
-	"iphone-17-pro": withKey("iphone-17-pro", profilePro),
-
-	"iphone-12-pro-max": withKey("iphone-12-pro-max", profileProMax),
-	"iphone-13-pro-max": withKey("iphone-13-pro-max", profileProMax),
-	"iphone-14-pro-max": withKey("iphone-14-pro-max", profileProMax),
-	"iphone-15-pro-max": withKey("iphone-15-pro-max", profileProMax),
-	"iphone-16-pro-max": {
-		Key: "iphone-16-pro-max", Name: "iPhone 16 Pro Max",
-		Width: 1320, Height: 2868,
-		ClockZoneRatio: 0.32, ButtonsZoneRatio: 0.80,
-		BottomInset: 34,
-	},
-	"iphone-17-pro-max": withKey("iphone-17-pro-max", profileProMax),
-
-	"iphone-air": withKey("iphone-air", profile15),
+func (d DeviceProfile) PointScale() float64 {
+	logical := d.LogicalWidth
+	if logical == 0 {
+		logical = pointsPerBaseWidth
+	}
+	return float64(d.Width) / float64(logical)
 }
 
-func withKey(key string, base DeviceProfile) DeviceProfile {
-	base.Key = key
-	return base
+func (d DeviceProfile) BottomInsetPx() int {
+	return int(float64(d.BottomInset) * d.PointScale())
 }
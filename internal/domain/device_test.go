@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestWidgetBand(t *testing.T) {
+	c := DefaultCatalog()
+	tests := []struct {
+		device string
+		layout WidgetLayout
+		want   int
+	}{
+		// The SE sets both ratios itself.
+		{"iphone-se-1", WidgetsInline, 34},
+		{"iphone-se-1", WidgetsRow, 107},
+		// The 12 mini only sets the row; inline falls back to the default.
+		{"iphone-12-mini", WidgetsInline, 58},
+		{"iphone-12-mini", WidgetsRow, 187},
+		{"iphone-se-1", WidgetsNone, 0},
+		{"iphone-12-mini", WidgetsNone, 0},
+		{"iphone-12-mini", WidgetLayout("stack"), 0},
+	}
+	for _, tt := range tests {
+		d, ok := c.Lookup(tt.device)
+		if !ok {
+			t.Fatalf("device %q missing", tt.device)
+		}
+		if got := d.WidgetBand(tt.layout); got != tt.want {
+			t.Errorf("%s WidgetBand(%q) = %d, want %d", tt.device, tt.layout, got, tt.want)
+		}
+		if got, want := d.GridTop(tt.layout), d.ClockBottom()+tt.want; got != want {
+			t.Errorf("%s GridTop(%q) = %d, want %d", tt.device, tt.layout, got, want)
+		}
+	}
+}
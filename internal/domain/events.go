@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Event marks a day in the calendar. A zero Year repeats it every year,
+// which is how fixed-date holidays are given.
+type Event struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseEvents reads "YYYY-MM-DD" and yearly "MM-DD" entries; each value
+// may hold several separated by commas. Malformed entries are skipped.
+func ParseEvents(values []string) []Event {
+	var out []Event
+	for _, v := range values {
+		for entry := range strings.SplitSeq(v, ",") {
+			entry = strings.TrimSpace(entry)
+			if t, err := time.Parse("2006-01-02", entry); err == nil {
+				out = append(out, Event{Year: t.Year(), Month: t.Month(), Day: t.Day()})
+				continue
+			}
+			// 2000 is a leap year, so "02-29" parses.
+			if t, err := time.Parse("2006-01-02", "2000-"+entry); err == nil {
+				out = append(out, Event{Month: t.Month(), Day: t.Day()})
+			}
+		}
+	}
+	return out
+}
+
+func (e Event) On(year int, month time.Month, day int) bool {
+	return (e.Year == 0 || e.Year == year) && e.Month == month && e.Day == day
+}
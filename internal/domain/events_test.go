@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEvents(t *testing.T) {
+	got := ParseEvents([]string{"2026-11-03, 12-25", "02-29,bogus,13-01", ""})
+	want := []Event{
+		{Year: 2026, Month: time.November, Day: 3},
+		{Month: time.December, Day: 25},
+		{Month: time.February, Day: 29},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseEvents = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHasEvent(t *testing.T) {
+	events := ParseEvents([]string{"2026-11-03,12-25"})
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	nov := BuildMonth(now, 2026, time.November, "en", WeekMonday)
+	if !nov.HasEvent(2, events) || nov.HasEvent(3, events) {
+		t.Error("one-off event should mark November 3 only")
+	}
+	for _, year := range []int{2026, 2027} {
+		if !BuildMonth(now, year, time.December, "en", WeekMonday).HasEvent(24, events) {
+			t.Errorf("yearly event not marked in December %d", year)
+		}
+	}
+	if BuildMonth(now, 2027, time.November, "en", WeekMonday).HasEvent(2, events) {
+		t.Error("one-off event repeated in another year")
+	}
+}
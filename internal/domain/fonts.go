@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+const DefaultFontFamily = "sf-pro"
+
+type FontWeight int
+
+const (
+	FontWeightRegular FontWeight = 400
+	FontWeightMedium  FontWeight = 500
+	FontWeightBold    FontWeight = 700
+)
+
+// ParseFontWeight accepts a name or a CSS-style number (100-900). Zero
+// means "not set" and lets the family pick its default weight.
+func ParseFontWeight(v string) FontWeight {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "regular", "normal":
+		return FontWeightRegular
+	case "medium":
+		return FontWeightMedium
+	case "bold":
+		return FontWeightBold
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 100 || n > 900 {
+		return 0
+	}
+	return FontWeight(n)
+}
+
+type FontSpec struct {
+	Family string
+	Weight FontWeight
+}
+
+func ParseFontSpec(family, weight string) FontSpec {
+	return FontSpec{
+		Family: strings.ToLower(strings.TrimSpace(family)),
+		Weight: ParseFontWeight(weight),
+	}
+}
+
+// Or fills unset fields from def.
+func (s FontSpec) Or(def FontSpec) FontSpec {
+	if s.Family == "" {
+		s.Family = def.Family
+	}
+	if s.Weight == 0 {
+		s.Weight = def.Weight
+	}
+	return s
+}
+
+func (s FontSpec) String() string {
+	family := s.Family
+	if family == "" {
+		family = DefaultFontFamily
+	}
+	if s.Weight == 0 {
+		return family
+	}
+	return family + ":" + strconv.Itoa(int(s.Weight))
+}
+
+// FontChoice selects the font for each kind of text on the wallpaper.
+type FontChoice struct {
+	Title   FontSpec
+	Numbers FontSpec
+	Footer  FontSpec
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "testing"
+
+func TestParseFontWeight(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FontWeight
+	}{
+		{"", 0},
+		{"regular", FontWeightRegular},
+		{"Bold", FontWeightBold},
+		{"medium", FontWeightMedium},
+		{"300", 300},
+		{"900", 900},
+		{"50", 0},
+		{"1000", 0},
+		{"heavy", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseFontWeight(tt.in); got != tt.want {
+			t.Errorf("ParseFontWeight(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFontSpecOr(t *testing.T) {
+	base := ParseFontSpec("Go-Mono", "regular")
+
+	if got := ParseFontSpec("", "").Or(base); got != base {
+		t.Errorf("empty spec = %+v, want %+v", got, base)
+	}
+	want := FontSpec{Family: "dejavu-serif", Weight: FontWeightRegular}
+	if got := ParseFontSpec("dejavu-serif", "").Or(base); got != want {
+		t.Errorf("family override = %+v, want %+v", got, want)
+	}
+	want = FontSpec{Family: "go-mono", Weight: FontWeightBold}
+	if got := ParseFontSpec("", "bold").Or(base); got != want {
+		t.Errorf("weight override = %+v, want %+v", got, want)
+	}
+}
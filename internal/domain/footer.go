@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type FooterPosition string
+
+const (
+	FooterBelow  FooterPosition = "below"
+	FooterAbove  FooterPosition = "above"
+	FooterHidden FooterPosition = "hidden"
+)
+
+func ParseFooterPosition(v string) FooterPosition {
+	switch FooterPosition(v) {
+	case FooterAbove, FooterHidden:
+		return FooterPosition(v)
+	default:
+		return FooterBelow
+	}
+}
+
+type FooterStyle string
+
+const (
+	FooterText FooterStyle = "text"
+	FooterBar  FooterStyle = "bar"
+)
+
+func ParseFooterStyle(v string) FooterStyle {
+	if FooterStyle(v) == FooterBar {
+		return FooterBar
+	}
+	return FooterText
+}
+
+const countdownDateLayout = "2006-01-02"
+
+// Countdown is a named target date for the {countdown:label} placeholder.
+type Countdown struct {
+	Label string
+	Date  time.Time
+}
+
+// ParseCountdowns reads "label:YYYY-MM-DD" entries; each value may hold
+// several separated by commas. Malformed entries are skipped.
+func ParseCountdowns(values []string) []Countdown {
+	var out []Countdown
+	for _, v := range values {
+		for entry := range strings.SplitSeq(v, ",") {
+			label, date, ok := strings.Cut(strings.TrimSpace(entry), ":")
+			if !ok || label == "" {
+				continue
+			}
+			t, err := time.Parse(countdownDateLayout, date)
+			if err != nil {
+				continue
+			}
+			out = append(out, Countdown{Label: label, Date: t})
+		}
+	}
+	return out
+}
+
+type Footer struct {
+	// Template is the footer text with placeholders; empty uses the
+	// language's default footer.
+	Template   string
+	Position   FooterPosition
+	Style      FooterStyle
+	Countdowns []Countdown
+}
+
+// Text expands the template for now in lang. Placeholders:
+//
+//	{days_left}         days left in the year
+//	{left}              the same as a phrase, e.g. "73 d left"
+//	{percent}           share of the year passed, e.g. "80%"
+//	{week}              ISO week number
+//	{day_of_year}       1-366
+//	{quarter}           1-4
+//	{date:LAYOUT}       now formatted with a Go time layout; {date}
+//	                    alone is the lock-screen date
+//	{countdown:LABEL}   days until the countdown named LABEL
+//
+// now is the start of the wallpaper's granularity period, so a
+// time-of-day layout such as {date:15:04} shows 00:00 under daily
+// granularity and the full hour under hourly. Unknown placeholders are
+// kept as written.
+func (f Footer) Text(now time.Time, lang string) string {
+	m := MessagesFor(lang)
+	day, left, percent := Progress(now)
+	if f.Template == "" {
+		return m.FooterText(left, percent)
+	}
+
+	var b strings.Builder
+	rest := f.Template
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			break
+		}
+		end += open
+
+		b.WriteString(rest[:open])
+		name, arg, _ := strings.Cut(rest[open+1:end], ":")
+		switch name {
+		case "days_left":
+			b.WriteString(m.Number(left))
+		case "left":
+			b.WriteString(m.DaysLeftText(left))
+		case "percent":
+			b.WriteString(m.PercentText(percent))
+		case "week":
+			_, week := now.ISOWeek()
+			b.WriteString(m.Number(week))
+		case "day_of_year":
+			b.WriteString(m.Number(day))
+		case "quarter":
+			b.WriteString(m.Number((int(now.Month())-1)/3 + 1))
+		case "date":
+			if arg == "" {
+				b.WriteString(m.Date(now))
+			} else {
+				b.WriteString(m.FormatTime(now, arg))
+			}
+		case "countdown":
+			if days, ok := f.daysUntil(now, arg); ok {
+				b.WriteString(m.Number(days))
+			} else {
+				b.WriteString(rest[open : end+1])
+			}
+		default:
+			b.WriteString(rest[open : end+1])
+		}
+		rest = rest[end+1:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// daysUntil counts calendar days from now to the countdown's date, or 0
+// once it has passed.
+func (f Footer) daysUntil(now time.Time, label string) (int, bool) {
+	for _, c := range f.Countdowns {
+		if c.Label != label {
+			continue
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		days := int(c.Date.Sub(today).Hours() / 24)
+		return max(days, 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFooterText(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	countdowns := ParseCountdowns([]string{"trip:2026-12-24, exam:2026-10-01", "bad", "x:2026-13-01"})
+
+	tests := []struct {
+		lang, template, want string
+	}{
+		{"en", "", "73 d left   80%"},
+		{"ru", "", "73 дня осталось   80%"},
+		{"en", "{days_left} days, week {week}, Q{quarter}", "73 days, week 43, Q4"},
+		{"en", "day {day_of_year}: {percent}", "day 292: 80%"},
+		{"de", "{left} · {percent}", "noch 73 Tage · 80\u00a0%"},
+		{"en", "{date:Jan 2}", "Oct 19"},
+		{"en", "{date}", "Monday, October 19"},
+		{"en", "{countdown:trip} to go", "66 to go"},
+		{"en", "{countdown:exam}", "0"},
+		{"en", "{countdown:nope} {unknown} {open", "{countdown:nope} {unknown} {open"},
+		{"ar", "{days_left}", "٧٣"},
+	}
+	for _, tt := range tests {
+		f := Footer{Template: tt.template, Countdowns: countdowns}
+		if got := f.Text(now, tt.lang); got != tt.want {
+			t.Errorf("Text(%s, %q) = %q, want %q", tt.lang, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestFooterDateGranularity(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 15, 42, 0, 0, time.UTC)
+	f := Footer{Template: "{date:Jan 2 15:04}"}
+	tests := []struct {
+		granularity Granularity
+		want        string
+	}{
+		{GranularityDay, "Oct 19 00:00"},
+		{GranularityHour, "Oct 19 15:00"},
+		{GranularityMinute, "Oct 19 15:42"},
+	}
+	for _, tt := range tests {
+		if got := f.Text(tt.granularity.Truncate(now), "en"); got != tt.want {
+			t.Errorf("%s: Text = %q, want %q", tt.granularity, got, tt.want)
+		}
+	}
+}
+
+func TestParseCountdowns(t *testing.T) {
+	got := ParseCountdowns([]string{"a:2026-01-02,b:2026-03-04", " c:2027-05-06 ", ":2026-01-01", "d"})
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("ParseCountdowns = %v, want labels %v", got, want)
+	}
+	for i, c := range got {
+		if c.Label != want[i] {
+			t.Errorf("countdown %d = %q, want %q", i, c.Label, want[i])
+		}
+	}
+}
+
+func TestParseFooterOptions(t *testing.T) {
+	if ParseFooterPosition("above") != FooterAbove || ParseFooterPosition("hidden") != FooterHidden || ParseFooterPosition("x") != FooterBelow {
+		t.Error("ParseFooterPosition")
+	}
+	if ParseFooterStyle("bar") != FooterBar || ParseFooterStyle("") != FooterText {
+		t.Error("ParseFooterStyle")
+	}
+}
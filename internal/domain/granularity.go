@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Granularity is how often a wallpaper changes: it is rendered as of the
+// start of the current period, so every request within one period gets
+// the same image.
+type Granularity string
+
+const (
+	GranularityDay    Granularity = "day"
+	GranularityHour   Granularity = "hour"
+	GranularityMinute Granularity = "minute"
+)
+
+// ParseGranularity defaults to hourly for the week view, whose progress
+// moves during the day, and to daily otherwise.
+func ParseGranularity(v string, mode RenderMode) Granularity {
+	switch Granularity(v) {
+	case GranularityDay, GranularityHour, GranularityMinute:
+		return Granularity(v)
+	}
+	if mode == ModeWeek {
+		return GranularityHour
+	}
+	return GranularityDay
+}
+
+// Truncate returns the start of the period holding t, in t's location.
+func (g Granularity) Truncate(t time.Time) time.Time {
+	switch g {
+	case GranularityMinute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	case GranularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
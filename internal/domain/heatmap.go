@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidHeatmap = errors.New("invalid heatmap")
+
+const (
+	HeatmapWeeks  = 53
+	HeatmapLevels = 5
+
+	maxHeatmapName = 64
+)
+
+// Date is a calendar day without a time or location.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func DateOf(t time.Time) Date {
+	return Date{t.Year(), t.Month(), t.Day()}
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(s))
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// HeatmapValues is one intensity per day, e.g. minutes exercised.
+type HeatmapValues map[Date]float64
+
+// ValidHeatmapName reports whether name can identify a stored data set:
+// 1-64 ASCII letters, digits, '-' and '_'.
+func ValidHeatmapName(name string) bool {
+	if name == "" || len(name) > maxHeatmapName {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ParseHeatmapValues reads a JSON object of "YYYY-MM-DD": value pairs, or
+// CSV rows of date,value with an optional header row.
+func ParseHeatmapValues(data []byte) (HeatmapValues, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var v HeatmapValues
+		if err := json.Unmarshal(trimmed, &v); err != nil {
+			if !errors.Is(err, ErrInvalidHeatmap) {
+				err = fmt.Errorf("%w: %w", ErrInvalidHeatmap, err)
+			}
+			return nil, err
+		}
+		return v, nil
+	}
+	return parseHeatmapCSV(bytes.NewReader(data))
+}
+
+func parseHeatmapCSV(r io.Reader) (HeatmapValues, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	values := make(HeatmapValues)
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHeatmap, err)
+		}
+		date, err := ParseDate(rec[0])
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidHeatmap, line, err)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(rec[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidHeatmap, line, err)
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%w: line %d: value %q is not a number", ErrInvalidHeatmap, line, rec[1])
+		}
+		values[date] = v
+	}
+}
+
+func (v HeatmapValues) MarshalJSON() ([]byte, error) {
+	m := make(map[string]float64, len(v))
+	for d, n := range v {
+		m[d.String()] = n
+	}
+	return json.Marshal(m)
+}
+
+func (v *HeatmapValues) UnmarshalJSON(data []byte) error {
+	var m map[string]float64
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidHeatmap, err)
+	}
+	*v = make(HeatmapValues, len(m))
+	for s, n := range m {
+		d, err := ParseDate(s)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidHeatmap, err)
+		}
+		(*v)[d] = n
+	}
+	return nil
+}
+
+type HeatCell struct {
+	Date time.Time
+	// Level is 0 for no activity and 1-4 for rising quarters of the
+	// busiest day in the grid.
+	Level  int
+	Future bool
+}
+
+// Heatmap is a year of days, one column per week and the current week
+// last, like a contribution graph.
+type Heatmap struct {
+	Weeks [HeatmapWeeks][7]HeatCell
+}
+
+func BuildHeatmap(now time.Time, values HeatmapValues, weekStart WeekStart) Heatmap {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := today.AddDate(0, 0, -weekStart.Column(today.Weekday())-(HeatmapWeeks-1)*7)
+
+	var peak float64
+	var h Heatmap
+	for w := range h.Weeks {
+		for d := range h.Weeks[w] {
+			date := first.AddDate(0, 0, w*7+d)
+			h.Weeks[w][d] = HeatCell{Date: date, Future: date.After(today)}
+			if v := values[DateOf(date)]; v > peak && !date.After(today) {
+				peak = v
+			}
+		}
+	}
+	if peak == 0 {
+		return h
+	}
+
+	for w := range h.Weeks {
+		for d := range h.Weeks[w] {
+			c := &h.Weeks[w][d]
+			if v := values[DateOf(c.Date)]; v > 0 && !c.Future {
+				c.Level = max(int(math.Ceil(v/peak*(HeatmapLevels-1))), 1)
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHeatmapValues(t *testing.T) {
+	want := HeatmapValues{
+		{2026, time.October, 19}: 30,
+		{2026, time.October, 20}: 12.5,
+	}
+	inputs := map[string]string{
+		"csv":        "2026-10-19,30\n2026-10-20, 12.5\n",
+		"csv header": "date,minutes\n2026-10-19,30\n2026-10-20,12.5",
+		"json":       ` {"2026-10-19": 30, "2026-10-20": 12.5}`,
+	}
+	for name, in := range inputs {
+		got, err := ParseHeatmapValues([]byte(in))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+		for d, v := range want {
+			if got[d] != v {
+				t.Errorf("%s: %s = %v, want %v", name, d, got[d], v)
+			}
+		}
+	}
+
+	for _, in := range []string{
+		"2026-10-19,30\n2026-13-01,5",
+		"2026-10-19,lots",
+		"2026-10-19,NaN",
+		"2026-10-19,30,extra",
+		`{"2026-10-19": "30"}`,
+		`{"yesterday": 30}`,
+		`{"2026-10-19": 30`,
+	} {
+		if _, err := ParseHeatmapValues([]byte(in)); !errors.Is(err, ErrInvalidHeatmap) {
+			t.Errorf("ParseHeatmapValues(%q) error = %v, want ErrInvalidHeatmap", in, err)
+		}
+	}
+}
+
+func TestHeatmapValuesJSON(t *testing.T) {
+	in := HeatmapValues{{2026, time.January, 2}: 3}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"2026-01-02":3}` {
+		t.Fatalf("Marshal = %s", data)
+	}
+	var out HeatmapValues
+	if err := json.Unmarshal(data, &out); err != nil || out[Date{2026, time.January, 2}] != 3 {
+		t.Fatalf("Unmarshal = %v, %v", out, err)
+	}
+}
+
+func TestValidHeatmapName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"runs":                  true,
+		"Pull_Ups-2026":         true,
+		"":                      false,
+		"../secrets":            false,
+		"a b":                   false,
+		"бег":                   false,
+		strings.Repeat("a", 65): false,
+	} {
+		if got := ValidHeatmapName(name); got != want {
+			t.Errorf("ValidHeatmapName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBuildHeatmap(t *testing.T) {
+	now := time.Date(2026, time.October, 21, 18, 0, 0, 0, time.UTC) // Wednesday
+	values := HeatmapValues{
+		{2026, time.October, 21}: 100, // busiest, today
+		{2026, time.October, 20}: 1,
+		{2026, time.October, 19}: 50,
+		{2026, time.October, 18}: -5,
+		{2026, time.October, 22}: 1000, // tomorrow does not count
+		{2025, time.October, 19}: 1000, // before the grid
+	}
+
+	h := BuildHeatmap(now, values, WeekMonday)
+	last := h.Weeks[HeatmapWeeks-1]
+	if last[0].Date.Day() != 19 || last[0].Date.Weekday() != time.Monday {
+		t.Fatalf("last week starts %v, want Monday 19", last[0].Date)
+	}
+	if first := h.Weeks[0][0].Date; first != time.Date(2025, time.October, 20, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("grid starts %v", first)
+	}
+
+	wantLevels := []int{2, 1, 4}
+	for d, want := range wantLevels {
+		if last[d].Level != want || last[d].Future {
+			t.Errorf("day %d: level %d future %v, want %d", d, last[d].Level, last[d].Future, want)
+		}
+	}
+	for d := 3; d < 7; d++ {
+		if !last[d].Future || last[d].Level != 0 {
+			t.Errorf("day %d: level %d future %v, want future", d, last[d].Level, last[d].Future)
+		}
+	}
+	if prev := h.Weeks[HeatmapWeeks-2][6]; prev.Level != 0 {
+		t.Errorf("negative value got level %d", prev.Level)
+	}
+
+	if got := BuildHeatmap(now, values, WeekSunday).Weeks[HeatmapWeeks-1][0].Date.Day(); got != 18 {
+		t.Errorf("Sunday-first week starts on %d, want 18", got)
+	}
+	if got := BuildHeatmap(now, nil, WeekMonday).Weeks[HeatmapWeeks-1][2].Level; got != 0 {
+		t.Errorf("empty values gave level %d", got)
+	}
+}
@@ -0,0 +1,428 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultLang = "en"
+
+// Messages holds the strings drawn on a wallpaper for one language.
+// Templates use {name} placeholders.
+type Messages struct {
+	Plural PluralRule
+
+	MonthsShort [12]string
+	MonthsLong  [12]string
+	// MonthsGenitive is the month form used inside dates; languages that
+	// don't inflect leave it empty and MonthsLong is used.
+	MonthsGenitive [12]string
+
+	// Weekdays, WeekdaysShort and WeekdayInitials start on Sunday, like
+	// time.Weekday. WeekdaysShort falls back to Weekdays when empty.
+	Weekdays        [7]string
+	WeekdaysShort   [7]string
+	WeekdayInitials [7]string
+
+	// DateFormat has {weekday}, {day} and {month}.
+	DateFormat string
+	// DaysLeft has {n}; Other is required, missing categories fall back
+	// to it.
+	DaysLeft map[PluralCategory]string
+	// PercentFormat has {n}.
+	PercentFormat string
+	// Footer has {left} and {percent}.
+	Footer string
+
+	// GroupSeparator splits thousands; Digits replaces 0-9 when set.
+	GroupSeparator string
+	Digits         string
+
+	// RTL languages mirror the month grid and day columns.
+	RTL bool
+}
+
+var messages = map[string]Messages{
+	"en": {
+		Plural:          pluralOneOther,
+		MonthsShort:     [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		MonthsLong:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		Weekdays:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		WeekdaysShort:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		WeekdayInitials: [7]string{"S", "M", "T", "W", "T", "F", "S"},
+		DateFormat:      "{weekday}, {month} {day}",
+		DaysLeft:        map[PluralCategory]string{PluralOther: "{n} d left"},
+		GroupSeparator:  ",",
+	},
+	"ru": {
+		Plural:          pluralEastSlavic,
+		MonthsShort:     [12]string{"Янв", "Фев", "Мар", "Апр", "Май", "Июн", "Июл", "Авг", "Сен", "Окт", "Ноя", "Дек"},
+		MonthsLong:      [12]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь", "Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"},
+		MonthsGenitive:  [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+		Weekdays:        [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+		WeekdaysShort:   [7]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+		WeekdayInitials: [7]string{"В", "П", "В", "С", "Ч", "П", "С"},
+		DateFormat:      "{weekday}, {day} {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "{n} день остался",
+			PluralFew:   "{n} дня осталось",
+			PluralMany:  "{n} дней осталось",
+			PluralOther: "{n} дня осталось",
+		},
+		GroupSeparator: "\u00a0",
+	},
+	"uk": {
+		Plural:          pluralEastSlavic,
+		MonthsShort:     [12]string{"Січ", "Лют", "Бер", "Кві", "Тра", "Чер", "Лип", "Сер", "Вер", "Жов", "Лис", "Гру"},
+		MonthsLong:      [12]string{"Січень", "Лютий", "Березень", "Квітень", "Травень", "Червень", "Липень", "Серпень", "Вересень", "Жовтень", "Листопад", "Грудень"},
+		MonthsGenitive:  [12]string{"січня", "лютого", "березня", "квітня", "травня", "червня", "липня", "серпня", "вересня", "жовтня", "листопада", "грудня"},
+		Weekdays:        [7]string{"неділя", "понеділок", "вівторок", "середа", "четвер", "пʼятниця", "субота"},
+		WeekdaysShort:   [7]string{"Нд", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+		WeekdayInitials: [7]string{"Н", "П", "В", "С", "Ч", "П", "С"},
+		DateFormat:      "{weekday}, {day} {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "{n} день залишився",
+			PluralFew:   "{n} дні залишилося",
+			PluralMany:  "{n} днів залишилося",
+			PluralOther: "{n} дня залишилося",
+		},
+		GroupSeparator: "\u00a0",
+	},
+	"pl": {
+		Plural:          pluralPolish,
+		MonthsShort:     [12]string{"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru"},
+		MonthsLong:      [12]string{"styczeń", "luty", "marzec", "kwiecień", "maj", "czerwiec", "lipiec", "sierpień", "wrzesień", "październik", "listopad", "grudzień"},
+		MonthsGenitive:  [12]string{"stycznia", "lutego", "marca", "kwietnia", "maja", "czerwca", "lipca", "sierpnia", "września", "października", "listopada", "grudnia"},
+		Weekdays:        [7]string{"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota"},
+		WeekdaysShort:   [7]string{"niedz.", "pon.", "wt.", "śr.", "czw.", "pt.", "sob."},
+		WeekdayInitials: [7]string{"N", "P", "W", "Ś", "C", "P", "S"},
+		DateFormat:      "{weekday}, {day} {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "pozostał {n} dzień",
+			PluralFew:   "pozostały {n} dni",
+			PluralMany:  "pozostało {n} dni",
+			PluralOther: "pozostało {n} dnia",
+		},
+		GroupSeparator: "\u00a0",
+	},
+	"de": {
+		Plural:          pluralOneOther,
+		MonthsShort:     [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		MonthsLong:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		Weekdays:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		WeekdaysShort:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		WeekdayInitials: [7]string{"S", "M", "D", "M", "D", "F", "S"},
+		DateFormat:      "{weekday}, {day}. {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "noch {n} Tag",
+			PluralOther: "noch {n} Tage",
+		},
+		PercentFormat:  "{n} %",
+		GroupSeparator: ".",
+	},
+	"fr": {
+		Plural:          pluralFrench,
+		MonthsShort:     [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		MonthsLong:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		Weekdays:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		WeekdaysShort:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		WeekdayInitials: [7]string{"D", "L", "M", "M", "J", "V", "S"},
+		DateFormat:      "{weekday} {day} {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "{n} jour restant",
+			PluralOther: "{n} jours restants",
+		},
+		PercentFormat:  "{n} %",
+		GroupSeparator: "\u00a0",
+	},
+	"es": {
+		Plural:          pluralOneOther,
+		MonthsShort:     [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		MonthsLong:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		Weekdays:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		WeekdaysShort:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		WeekdayInitials: [7]string{"D", "L", "M", "X", "J", "V", "S"},
+		DateFormat:      "{weekday}, {day} de {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "queda {n} día",
+			PluralOther: "quedan {n} días",
+		},
+		PercentFormat:  "{n} %",
+		GroupSeparator: ".",
+	},
+	"it": {
+		Plural:          pluralOneOther,
+		MonthsShort:     [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		MonthsLong:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		Weekdays:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		WeekdaysShort:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		WeekdayInitials: [7]string{"D", "L", "M", "M", "G", "V", "S"},
+		DateFormat:      "{weekday} {day} {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "manca {n} giorno",
+			PluralOther: "mancano {n} giorni",
+		},
+		GroupSeparator: ".",
+	},
+	"pt":    portuguese,
+	"pt-pt": withPlural(portuguese, pluralOneOther),
+	"tr": {
+		Plural:          pluralOther,
+		MonthsShort:     [12]string{"Oca", "Şub", "Mar", "Nis", "May", "Haz", "Tem", "Ağu", "Eyl", "Eki", "Kas", "Ara"},
+		MonthsLong:      [12]string{"Ocak", "Şubat", "Mart", "Nisan", "Mayıs", "Haziran", "Temmuz", "Ağustos", "Eylül", "Ekim", "Kasım", "Aralık"},
+		Weekdays:        [7]string{"Pazar", "Pazartesi", "Salı", "Çarşamba", "Perşembe", "Cuma", "Cumartesi"},
+		WeekdaysShort:   [7]string{"Paz", "Pzt", "Sal", "Çar", "Per", "Cum", "Cmt"},
+		WeekdayInitials: [7]string{"P", "P", "S", "Ç", "P", "C", "C"},
+		DateFormat:      "{day} {month} {weekday}",
+		DaysLeft:        map[PluralCategory]string{PluralOther: "{n} gün kaldı"},
+		PercentFormat:   "%{n}",
+		GroupSeparator:  ".",
+	},
+	"ja": {
+		Plural:          pluralOther,
+		MonthsShort:     [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		MonthsLong:      [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Weekdays:        [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		WeekdaysShort:   [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		WeekdayInitials: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		DateFormat:      "{month}{day}日 {weekday}",
+		DaysLeft:        map[PluralCategory]string{PluralOther: "残り{n}日"},
+		GroupSeparator:  ",",
+	},
+	"zh": {
+		Plural:          pluralOther,
+		MonthsShort:     [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		MonthsLong:      [12]string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+		MonthsGenitive:  [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Weekdays:        [7]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		WeekdaysShort:   [7]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+		WeekdayInitials: [7]string{"日", "一", "二", "三", "四", "五", "六"},
+		DateFormat:      "{month}{day}日 {weekday}",
+		DaysLeft:        map[PluralCategory]string{PluralOther: "还剩{n}天"},
+		GroupSeparator:  ",",
+	},
+	"ko": {
+		Plural:          pluralOther,
+		MonthsShort:     [12]string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+		MonthsLong:      [12]string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+		Weekdays:        [7]string{"일요일", "월요일", "화요일", "수요일", "목요일", "금요일", "토요일"},
+		WeekdaysShort:   [7]string{"일", "월", "화", "수", "목", "금", "토"},
+		WeekdayInitials: [7]string{"일", "월", "화", "수", "목", "금", "토"},
+		DateFormat:      "{month} {day}일 {weekday}",
+		DaysLeft:        map[PluralCategory]string{PluralOther: "{n}일 남음"},
+		GroupSeparator:  ",",
+	},
+	"ar": {
+		RTL:             true,
+		Plural:          pluralArabic,
+		MonthsShort:     [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		MonthsLong:      [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		Weekdays:        [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+		WeekdayInitials: [7]string{"ح", "ن", "ث", "ر", "خ", "ج", "س"},
+		DateFormat:      "{weekday}، {day} {month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralZero:  "لم يتبق أي يوم",
+			PluralOne:   "بقي يوم واحد",
+			PluralTwo:   "بقي يومان",
+			PluralFew:   "بقي {n} أيام",
+			PluralMany:  "بقي {n} يومًا",
+			PluralOther: "بقي {n} يوم",
+		},
+		GroupSeparator: "٬",
+		Digits:         "٠١٢٣٤٥٦٧٨٩",
+		PercentFormat:  "{n}٪",
+	},
+	"he": {
+		RTL:             true,
+		Plural:          pluralHebrew,
+		MonthsShort:     [12]string{"ינו׳", "פבר׳", "מרץ", "אפר׳", "מאי", "יוני", "יולי", "אוג׳", "ספט׳", "אוק׳", "נוב׳", "דצמ׳"},
+		MonthsLong:      [12]string{"ינואר", "פברואר", "מרץ", "אפריל", "מאי", "יוני", "יולי", "אוגוסט", "ספטמבר", "אוקטובר", "נובמבר", "דצמבר"},
+		Weekdays:        [7]string{"יום ראשון", "יום שני", "יום שלישי", "יום רביעי", "יום חמישי", "יום שישי", "שבת"},
+		WeekdayInitials: [7]string{"א׳", "ב׳", "ג׳", "ד׳", "ה׳", "ו׳", "ש׳"},
+		DateFormat:      "{weekday}, {day} ב{month}",
+		DaysLeft: map[PluralCategory]string{
+			PluralOne:   "נותר יום אחד",
+			PluralTwo:   "נותרו יומיים",
+			PluralOther: "נותרו {n} ימים",
+		},
+		GroupSeparator: ",",
+	},
+}
+
+var portuguese = Messages{
+	Plural:          pluralFrench,
+	MonthsShort:     [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+	MonthsLong:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	Weekdays:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+	WeekdaysShort:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+	WeekdayInitials: [7]string{"D", "S", "T", "Q", "Q", "S", "S"},
+	DateFormat:      "{weekday}, {day} de {month}",
+	DaysLeft: map[PluralCategory]string{
+		PluralOne:   "falta {n} dia",
+		PluralOther: "faltam {n} dias",
+	},
+	GroupSeparator: ".",
+}
+
+// European Portuguese only treats 1 as singular; Brazilian also 0.
+func withPlural(m Messages, rule PluralRule) Messages {
+	m.Plural = rule
+	return m
+}
+
+// NormalizeLang maps a language tag to a catalog key by dropping subtags
+// until one matches: "pt-BR" -> "pt" -> "en".
+func NormalizeLang(lang string) string {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	for tag != "" {
+		if _, ok := messages[tag]; ok {
+			return tag
+		}
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return DefaultLang
+}
+
+func MessagesFor(lang string) Messages {
+	return messages[NormalizeLang(lang)]
+}
+
+// Languages returns the catalog keys.
+func Languages() []string {
+	langs := make([]string, 0, len(messages))
+	for lang := range messages {
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+func (m Messages) Date(t time.Time) string {
+	month := m.MonthsGenitive[t.Month()-1]
+	if month == "" {
+		month = m.MonthsLong[t.Month()-1]
+	}
+	return strings.NewReplacer(
+		"{weekday}", m.Weekdays[t.Weekday()],
+		"{day}", m.Number(t.Day()),
+		"{month}", month,
+	).Replace(m.DateFormat)
+}
+
+// WeekdayHeader returns the weekday initials of a week starting on first.
+func (m Messages) WeekdayHeader(first time.Weekday) [7]string {
+	var out [7]string
+	for i := range out {
+		out[i] = m.WeekdayInitials[(int(first)+i)%7]
+	}
+	return out
+}
+
+// Number formats n with the language's digits and thousands separator.
+func (m Messages) Number(n int) string {
+	s := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i := range len(s) {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(m.GroupSeparator)
+		}
+		b.WriteByte(s[i])
+	}
+	return m.localizeDigits(b.String())
+}
+
+// FormatTime is time.Format with month and weekday names taken from the
+// catalog. "January", "Jan", "Monday" and "Mon" in layout become the long
+// month (genitive where the language has one), short month, weekday and
+// short weekday.
+func (m Messages) FormatTime(t time.Time, layout string) string {
+	month := m.MonthsGenitive[t.Month()-1]
+	if month == "" {
+		month = m.MonthsLong[t.Month()-1]
+	}
+	weekdayShort := m.WeekdaysShort[t.Weekday()]
+	if weekdayShort == "" {
+		weekdayShort = m.Weekdays[t.Weekday()]
+	}
+	names := []struct{ token, value string }{
+		{"January", month},
+		{"Jan", m.MonthsShort[t.Month()-1]},
+		{"Monday", m.Weekdays[t.Weekday()]},
+		{"Mon", weekdayShort},
+	}
+
+	var b strings.Builder
+	start := 0
+	for i := 0; i < len(layout); {
+		matched := false
+		for _, n := range names {
+			if strings.HasPrefix(layout[i:], n.token) {
+				b.WriteString(m.localizeDigits(t.Format(layout[start:i])))
+				b.WriteString(n.value)
+				i += len(n.token)
+				start = i
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	b.WriteString(m.localizeDigits(t.Format(layout[start:])))
+	return b.String()
+}
+
+func (m Messages) localizeDigits(s string) string {
+	digits := []rune(m.Digits)
+	if len(digits) != 10 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return digits[r-'0']
+		}
+		return r
+	}, s)
+}
+
+func (m Messages) DaysLeftText(n int) string {
+	form, ok := m.DaysLeft[m.Plural(n)]
+	if !ok {
+		form = m.DaysLeft[PluralOther]
+	}
+	return strings.ReplaceAll(form, "{n}", m.Number(n))
+}
+
+func (m Messages) PercentText(n int) string {
+	format := m.PercentFormat
+	if format == "" {
+		format = "{n}%"
+	}
+	return strings.ReplaceAll(format, "{n}", m.Number(n))
+}
+
+func (m Messages) FooterText(left, percent int) string {
+	format := m.Footer
+	if format == "" {
+		format = "{left}   {percent}"
+	}
+	return strings.NewReplacer(
+		"{left}", m.DaysLeftText(left),
+		"{percent}", m.PercentText(percent),
+	).Replace(format)
+}
+
+func LockScreenDate(t time.Time, lang string) string {
+	return MessagesFor(lang).Date(t)
+}
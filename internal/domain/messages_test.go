@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeLang(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "en"},
+		{"en", "en"},
+		{"ru", "ru"},
+		{"pt-BR", "pt"},
+		{"pt_br", "pt"},
+		{"pt-PT", "pt-pt"},
+		{"zh-Hans-CN", "zh"},
+		{"DE", "de"},
+		{"xx-YY", "en"},
+		{"-", "en"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLang(tt.in); got != tt.want {
+			t.Errorf("NormalizeLang(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want PluralCategory
+	}{
+		{"en", 0, PluralOther},
+		{"en", 1, PluralOne},
+		{"fr", 0, PluralOne},
+		{"pt", 0, PluralOne},
+		{"pt-pt", 0, PluralOther},
+		{"ru", 1, PluralOne},
+		{"ru", 21, PluralOne},
+		{"ru", 11, PluralMany},
+		{"ru", 3, PluralFew},
+		{"ru", 13, PluralMany},
+		{"ru", 104, PluralFew},
+		{"ru", 0, PluralMany},
+		{"pl", 1, PluralOne},
+		{"pl", 21, PluralMany},
+		{"pl", 22, PluralFew},
+		{"ar", 0, PluralZero},
+		{"ar", 2, PluralTwo},
+		{"ar", 103, PluralFew},
+		{"ar", 111, PluralMany},
+		{"ar", 100, PluralOther},
+		{"he", 2, PluralTwo},
+		{"ja", 1, PluralOther},
+	}
+	for _, tt := range tests {
+		if got := MessagesFor(tt.lang).Plural(tt.n); got != tt.want {
+			t.Errorf("%s plural(%d) = %v, want %v", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMessagesComplete(t *testing.T) {
+	for _, lang := range Languages() {
+		m := messages[lang]
+		if m.Plural == nil {
+			t.Errorf("%s: no plural rule", lang)
+		}
+		if _, ok := m.DaysLeft[PluralOther]; !ok {
+			t.Errorf("%s: DaysLeft has no Other form", lang)
+		}
+		for i := range 12 {
+			if m.MonthsShort[i] == "" || m.MonthsLong[i] == "" {
+				t.Errorf("%s: month %d has no name", lang, i+1)
+			}
+		}
+		for i := range 7 {
+			if m.Weekdays[i] == "" || m.WeekdayInitials[i] == "" {
+				t.Errorf("%s: weekday %d has no name", lang, i)
+			}
+		}
+		if d := m.Date(time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)); strings.Contains(d, "{") {
+			t.Errorf("%s: date %q has unexpanded placeholders", lang, d)
+		}
+		for n := range 400 {
+			if s := m.FooterText(n, 50); strings.Contains(s, "{") {
+				t.Errorf("%s: footer %q has unexpanded placeholders", lang, s)
+				break
+			}
+		}
+	}
+}
+
+func TestMessagesText(t *testing.T) {
+	day := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		got, want string
+	}{
+		{LockScreenDate(day, "en"), "Monday, October 19"},
+		{LockScreenDate(day, "ru"), "понедельник, 19 октября"},
+		{LockScreenDate(day, "de"), "Montag, 19. Oktober"},
+		{LockScreenDate(day, "ja"), "10月19日 月曜日"},
+		{MessagesFor("en").FooterText(73, 80), "73 d left   80%"},
+		{MessagesFor("ru").FooterText(1, 99), "1 день остался   99%"},
+		{MessagesFor("ru").FooterText(22, 94), "22 дня осталось   94%"},
+		{MessagesFor("ru").FooterText(0, 100), "0 дней осталось   100%"},
+		{MessagesFor("tr").FooterText(5, 98), "5 gün kaldı   %98"},
+		{MessagesFor("ar").FooterText(2, 99), "بقي يومان   ٩٩٪"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 7, "7"},
+		{"en", 1234567, "1,234,567"},
+		{"en", -1200, "-1,200"},
+		{"de", 1000, "1.000"},
+		{"ru", 12345, "12 345"},
+		{"ar", 1234, "١٬٢٣٤"},
+	}
+	for _, tt := range tests {
+		if got := MessagesFor(tt.lang).Number(tt.n); got != tt.want {
+			t.Errorf("Number(%s, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 9, 5, 0, 0, time.UTC)
+	tests := []struct {
+		lang, layout, want string
+	}{
+		{"en", "Mon 2 Jan 2006", "Mon 19 Oct 2026"},
+		{"en", "Monday, January 2", "Monday, October 19"},
+		{"ru", "2 January, Mon", "19 октября, Пн"},
+		{"de", "Mon 02.01.", "Mo. 19.10."},
+		{"ar", "2 January", "١٩ أكتوبر"},
+		{"en", "15:04", "09:05"},
+	}
+	for _, tt := range tests {
+		if got := MessagesFor(tt.lang).FormatTime(now, tt.layout); got != tt.want {
+			t.Errorf("FormatTime(%s, %q) = %q, want %q", tt.lang, tt.layout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package domain
+
+type RenderMode string
+
+const (
+	ModeMonths  RenderMode = "months"
+	ModeMonth   RenderMode = "month"
+	ModeWeek    RenderMode = "week"
+	ModeHeatmap RenderMode = "heatmap"
+)
+
+func ParseRenderMode(v string) RenderMode {
+	switch RenderMode(v) {
+	case ModeMonth, ModeWeek, ModeHeatmap:
+		return RenderMode(v)
+	default:
+		return ModeMonths
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+// PluralCategory is a CLDR plural category.
+type PluralCategory int
+
+const (
+	PluralOther PluralCategory = iota
+	PluralZero
+	PluralOne
+	PluralTwo
+	PluralFew
+	PluralMany
+)
+
+// PluralRule picks the CLDR cardinal category for a non-negative integer.
+type PluralRule func(n int) PluralCategory
+
+func pluralOther(int) PluralCategory {
+	return PluralOther
+}
+
+func pluralOneOther(n int) PluralCategory {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// French and Brazilian Portuguese treat 0 as singular too.
+func pluralFrench(n int) PluralCategory {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralEastSlavic(n int) PluralCategory {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralPolish(n int) PluralCategory {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralArabic(n int) PluralCategory {
+	mod100 := n % 100
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
+
+func pluralHebrew(n int) PluralCategory {
+	switch n {
+	case 1:
+		return PluralOne
+	case 2:
+		return PluralTwo
+	default:
+		return PluralOther
+	}
+}
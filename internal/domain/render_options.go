@@ -0,0 +1,30 @@
+package domain
+
+type RenderOptions struct {
+	Mode      RenderMode
+	Lang      string
+	Weekends  string
+	DayStyle  DayStyle
+	UIScale   float64
+	BgStyle   BackgroundStyle
+	BgColor   string
+	Widgets   WidgetLayout
+	Screen    Screen
+	HomeStyle HomeStyle
+	Preview   bool
+	Fonts     FontChoice
+	Footer    Footer
+
+	WeekStart   WeekStart
+	WeekNumbers bool
+	// WeekdayHeader adds a row of weekday initials above each month.
+	WeekdayHeader bool
+
+	// Events are marked on the days they fall on in month mode.
+	Events []Event
+
+	Heatmap HeatmapValues
+	// HeatmapColor is the accent of the heatmap scale: a weekend color
+	// name, "today" or #rrggbb.
+	HeatmapColor string
+}
@@ -0,0 +1,71 @@
+package domain
+
+type Screen string
+
+const (
+	ScreenLock Screen = "lock"
+	ScreenHome Screen = "home"
+)
+
+func ParseScreen(v string) Screen {
+	if Screen(v) == ScreenHome {
+		return ScreenHome
+	}
+	return ScreenLock
+}
+
+type HomeStyle string
+
+const (
+	HomeDock   HomeStyle = "dock"
+	HomeStatus HomeStyle = "status"
+	HomeDimmed HomeStyle = "dimmed"
+)
+
+func ParseHomeStyle(v string) HomeStyle {
+	switch HomeStyle(v) {
+	case HomeStatus, HomeDimmed:
+		return HomeStyle(v)
+	default:
+		return HomeDock
+	}
+}
+
+const (
+	defaultStatusBarRatio   = 0.06
+	defaultIconGridTopRatio = 0.09
+	defaultDockTopRatio     = 0.87
+
+	IconGridColumns = 4
+	IconGridRows    = 6
+)
+
+type HomeGeometry struct {
+	StatusBarBottom int
+	IconGridTop     int
+	IconGridBottom  int
+	DockTop         int
+	DockBottom      int
+}
+
+func (d DeviceProfile) HomeGeometry() HomeGeometry {
+	status := orDefault(d.StatusBarRatio, defaultStatusBarRatio)
+	iconTop := orDefault(d.IconGridTopRatio, defaultIconGridTopRatio)
+	dockTop := orDefault(d.DockTopRatio, defaultDockTopRatio)
+
+	h := float64(d.Height)
+	return HomeGeometry{
+		StatusBarBottom: int(h * status),
+		IconGridTop:     int(h * iconTop),
+		IconGridBottom:  int(h * dockTop),
+		DockTop:         int(h * dockTop),
+		DockBottom:      d.Height - d.BottomInsetPx(),
+	}
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -29,3 +29,21 @@ func IOSTheme() Theme {
 		WeekendRed:   color.RGBA{200, 90, 90, 255},
 	}
 }
+
+// HeatScale is the five colors of a heatmap, from no activity to the
+// busiest days: the empty step is a dim Future, the rest fade accent in
+// over the background in quarters.
+func (t Theme) HeatScale(accent color.RGBA) [5]color.RGBA {
+	scale := [5]color.RGBA{blend(t.Background, t.Future, 0.5)}
+	for i := 1; i < len(scale); i++ {
+		scale[i] = blend(t.Background, accent, float64(i)/float64(len(scale)-1))
+	}
+	return scale
+}
+
+func blend(from, to color.RGBA, k float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*k + 0.5)
+	}
+	return color.RGBA{mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), mix(from.A, to.A)}
+}
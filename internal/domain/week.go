@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+type DayData struct {
+	Date    time.Time
+	Weekday time.Weekday
+	// Name is the short weekday name.
+	Name string
+	// Passed is the share of the day gone by, from 0 to 1.
+	Passed  float64
+	IsToday bool
+}
+
+// BuildWeek lays out the week holding now, starting on weekStart.
+func BuildWeek(now time.Time, lang string, weekStart WeekStart) []DayData {
+	msgs := MessagesFor(lang)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := today.AddDate(0, 0, -weekStart.Column(today.Weekday()))
+
+	days := make([]DayData, 7)
+	for i := range days {
+		date := first.AddDate(0, 0, i)
+		name := msgs.WeekdaysShort[date.Weekday()]
+		if name == "" {
+			name = msgs.Weekdays[date.Weekday()]
+		}
+
+		var passed float64
+		switch {
+		case date.Before(today):
+			passed = 1
+		case date.Equal(today):
+			passed = now.Sub(today).Hours() / 24
+		}
+
+		days[i] = DayData{
+			Date:    date,
+			Weekday: date.Weekday(),
+			Name:    name,
+			Passed:  passed,
+			IsToday: date.Equal(today),
+		}
+	}
+	return days
+}
+
+// WeekProgress is the share of the week gone by, from 0 to 1.
+func WeekProgress(days []DayData) float64 {
+	var sum float64
+	for _, d := range days {
+		sum += d.Passed
+	}
+	return sum / float64(len(days))
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+type WeekStart string
+
+const (
+	WeekMonday   WeekStart = "mon"
+	WeekSunday   WeekStart = "sun"
+	WeekSaturday WeekStart = "sat"
+)
+
+func ParseWeekStart(v string) WeekStart {
+	switch WeekStart(v) {
+	case WeekSunday, WeekSaturday:
+		return WeekStart(v)
+	default:
+		return WeekMonday
+	}
+}
+
+// Weekday of the first grid column; the zero value is Monday.
+func (w WeekStart) Weekday() time.Weekday {
+	switch w {
+	case WeekSunday:
+		return time.Sunday
+	case WeekSaturday:
+		return time.Saturday
+	default:
+		return time.Monday
+	}
+}
+
+// Column of weekday d in a grid starting on w.
+func (w WeekStart) Column(d time.Weekday) int {
+	return (int(d) - int(w.Weekday()) + 7) % 7
+}
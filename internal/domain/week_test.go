@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildWeek(t *testing.T) {
+	now := time.Date(2026, time.October, 21, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		start WeekStart
+		first int
+		today int
+	}{
+		{WeekMonday, 19, 2},
+		{WeekSunday, 18, 3},
+		{WeekSaturday, 17, 4},
+	}
+	for _, tt := range tests {
+		days := BuildWeek(now, "en", tt.start)
+		if len(days) != 7 || days[0].Date.Day() != tt.first || days[0].Weekday != tt.start.Weekday() {
+			t.Fatalf("%s: week starts %v, want day %d", tt.start, days[0].Date, tt.first)
+		}
+		for i, d := range days {
+			var want float64
+			switch {
+			case i < tt.today:
+				want = 1
+			case i == tt.today:
+				want = 0.75
+			}
+			if d.Passed != want || d.IsToday != (i == tt.today) {
+				t.Errorf("%s day %d: passed %v today %v, want %v %v", tt.start, i, d.Passed, d.IsToday, want, i == tt.today)
+			}
+		}
+	}
+
+	if got := BuildWeek(now, "ru", WeekMonday)[0].Name; got != "Пн" {
+		t.Errorf("ru Monday = %q, want Пн", got)
+	}
+	if got := WeekProgress(BuildWeek(now, "en", WeekMonday)); got != 2.75/7 {
+		t.Errorf("WeekProgress = %v, want %v", got, 2.75/7)
+	}
+}
+
+func TestGranularity(t *testing.T) {
+	now := time.Date(2026, time.October, 21, 18, 42, 13, 0, time.FixedZone("user", 3*3600))
+	tests := []struct {
+		v    string
+		mode RenderMode
+		want time.Time
+	}{
+		{"", ModeMonths, time.Date(2026, time.October, 21, 0, 0, 0, 0, now.Location())},
+		{"", ModeWeek, time.Date(2026, time.October, 21, 18, 0, 0, 0, now.Location())},
+		{"minute", ModeMonths, time.Date(2026, time.October, 21, 18, 42, 0, 0, now.Location())},
+		{"day", ModeWeek, time.Date(2026, time.October, 21, 0, 0, 0, 0, now.Location())},
+		{"bogus", ModeMonth, time.Date(2026, time.October, 21, 0, 0, 0, 0, now.Location())},
+	}
+	for _, tt := range tests {
+		if got := ParseGranularity(tt.v, tt.mode).Truncate(now); !got.Equal(tt.want) {
+			t.Errorf("%q/%s: %v, want %v", tt.v, tt.mode, got, tt.want)
+		}
+	}
+}
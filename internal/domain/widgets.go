@@ -0,0 +1,23 @@
+package domain
+
+type WidgetLayout string
+
+const (
+	WidgetsNone   WidgetLayout = "none"
+	WidgetsInline WidgetLayout = "inline"
+	WidgetsRow    WidgetLayout = "row"
+)
+
+const (
+	defaultInlineWidgetRatio = 0.025
+	defaultWidgetRowRatio    = 0.075
+)
+
+func ParseWidgetLayout(v string) WidgetLayout {
+	switch WidgetLayout(v) {
+	case WidgetsInline, WidgetsRow:
+		return WidgetLayout(v)
+	default:
+		return WidgetsNone
+	}
+}
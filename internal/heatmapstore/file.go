@@ -0,0 +1,63 @@
+package heatmapstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+// FileStore keeps each data set in Dir as <name>.json. A hand-made
+// <name>.csv is read too, but an uploaded .json takes precedence.
+type FileStore struct {
+	Dir string
+}
+
+func (s FileStore) Load(ctx context.Context, name string) (domain.HeatmapValues, error) {
+	for _, ext := range []string{".json", ".csv"} {
+		data, err := os.ReadFile(filepath.Join(s.Dir, name+ext))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values, err := domain.ParseHeatmapValues(data)
+		if err != nil {
+			return nil, fmt.Errorf("heatmap %s%s: %w", name, ext, err)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("heatmap %s: %w", name, fs.ErrNotExist)
+}
+
+// Save writes through a temporary file so readers never see a partial set.
+func (s FileStore) Save(ctx context.Context, name string, values domain.HeatmapValues) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.Dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.Dir, name+".json"))
+}
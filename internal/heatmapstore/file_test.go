@@ -0,0 +1,51 @@
+package heatmapstore
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store := FileStore{Dir: filepath.Join(t.TempDir(), "heatmaps")}
+
+	if _, err := store.Load(ctx, "runs"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load missing = %v, want ErrNotExist", err)
+	}
+
+	// A hand-made CSV is read until an upload replaces it.
+	if err := os.MkdirAll(store.Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.Dir, "runs.csv"), []byte("date,km\n2026-10-19,5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	day := domain.Date{Year: 2026, Month: time.October, Day: 19}
+	got, err := store.Load(ctx, "runs")
+	if err != nil || got[day] != 5 {
+		t.Fatalf("Load csv = %v, %v", got, err)
+	}
+
+	if err := store.Save(ctx, "runs", domain.HeatmapValues{day: 7}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = store.Load(ctx, "runs")
+	if err != nil || len(got) != 1 || got[day] != 7 {
+		t.Fatalf("Load saved = %v, %v", got, err)
+	}
+
+	entries, err := os.ReadDir(store.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("store dir has %d entries, want runs.csv and runs.json", len(entries))
+	}
+}
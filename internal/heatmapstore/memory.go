@@ -0,0 +1,39 @@
+package heatmapstore
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"sync"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+// Memory keeps data sets for the life of the process.
+type Memory struct {
+	mu   sync.Mutex
+	sets map[string]domain.HeatmapValues
+}
+
+func (m *Memory) Load(ctx context.Context, name string) (domain.HeatmapValues, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values, ok := m.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("heatmap %s: %w", name, fs.ErrNotExist)
+	}
+	return maps.Clone(values), nil
+}
+
+func (m *Memory) Save(ctx context.Context, name string, values domain.HeatmapValues) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sets == nil {
+		m.sets = make(map[string]domain.HeatmapValues)
+	}
+	m.sets[name] = maps.Clone(values)
+	return nil
+}
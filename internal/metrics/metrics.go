@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/rendering"
+)
+
+var (
+	durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+	sizeBuckets     = []float64{16 << 10, 32 << 10, 64 << 10, 128 << 10, 256 << 10, 512 << 10, 1 << 20, 2 << 20, 4 << 20}
+)
+
+type Metrics struct {
+	Registry *Registry
+
+	requests        *CounterVec
+	requestDuration *HistogramVec
+	renders         *CounterVec
+	renderDuration  *HistogramVec
+	encodedBytes    *HistogramVec
+}
+
+func New() *Metrics {
+	r := NewRegistry()
+
+	m := &Metrics{
+		Registry: r,
+		requests: r.NewCounterVec(
+			"http_requests_total",
+			"HTTP requests by route, method and status code.",
+			"route", "method", "status",
+		),
+		requestDuration: r.NewHistogramVec(
+			"http_request_duration_seconds",
+			"HTTP request latency by route.",
+			durationBuckets,
+			"route",
+		),
+		renders: r.NewCounterVec(
+			"wallpaper_renders_total",
+			"Rendered wallpapers by normalized parameters.",
+			"device", "style", "bg", "lang", "weekends",
+		),
+		renderDuration: r.NewHistogramVec(
+			"wallpaper_render_duration_seconds",
+			"Wallpaper render time by device and background style.",
+			durationBuckets,
+			"device", "bg",
+		),
+		encodedBytes: r.NewHistogramVec(
+			"wallpaper_encoded_bytes",
+			"Size of encoded wallpaper images.",
+			sizeBuckets,
+			"device", "format",
+		),
+	}
+
+	r.NewCounterFunc(
+		"font_cache_hits_total",
+		"Font face cache hits.",
+		func() float64 { return float64(rendering.ReadFontCacheStats().Hits) },
+	)
+	r.NewCounterFunc(
+		"font_cache_misses_total",
+		"Font face cache misses.",
+		func() float64 { return float64(rendering.ReadFontCacheStats().Misses) },
+	)
+	r.NewGaugeFunc(
+		"font_cache_entries",
+		"Font face sets currently cached.",
+		func() float64 { return float64(rendering.ReadFontCacheStats().Entries) },
+	)
+	r.NewGaugeFunc(
+		"font_cache_hit_ratio",
+		"Font face cache hits divided by lookups.",
+		func() float64 {
+			s := rendering.ReadFontCacheStats()
+			if s.Hits+s.Misses == 0 {
+				return 0
+			}
+			return float64(s.Hits) / float64(s.Hits+s.Misses)
+		},
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return m.Registry.Handler()
+}
+
+func (m *Metrics) ObserveRequest(route, method string, status int, d time.Duration) {
+	m.requests.Inc(route, method, statusLabel(status))
+	m.requestDuration.Observe(d.Seconds(), route)
+}
+
+func (m *Metrics) ObserveRender(device domain.DeviceProfile, style domain.DayStyle, bg domain.BackgroundStyle, lang, weekends string, d time.Duration) {
+	m.renders.Inc(device.Key, string(style), string(bg), lang, weekends)
+	m.renderDuration.Observe(d.Seconds(), device.Key, string(bg))
+}
+
+func (m *Metrics) ObserveEncoded(device, format string, bytes int) {
+	m.encodedBytes.Observe(float64(bytes), device, format)
+}
+
+func statusLabel(status int) string {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return strconv.Itoa(status)
+}
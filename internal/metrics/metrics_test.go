@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/wallpaper", http.MethodGet, 0, 30*time.Millisecond)
+	m.ObserveRequest("/wallpaper", http.MethodGet, http.StatusNotModified, 2*time.Millisecond)
+	m.ObserveRender(domain.DeviceProfile{Key: "iphone-15"}, domain.DayDots, domain.BgIOS, "en", "off", 40*time.Millisecond)
+	m.ObserveEncoded("iphone-15", "png", 100<<10)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE http_requests_total counter\n",
+		`http_requests_total{route="/wallpaper",method="GET",status="200"} 1` + "\n",
+		`http_requests_total{route="/wallpaper",method="GET",status="304"} 1` + "\n",
+		"# TYPE http_request_duration_seconds histogram\n",
+		`http_request_duration_seconds_bucket{route="/wallpaper",le="0.005"} 1` + "\n",
+		`http_request_duration_seconds_bucket{route="/wallpaper",le="0.05"} 2` + "\n",
+		`http_request_duration_seconds_count{route="/wallpaper"} 2` + "\n",
+		`wallpaper_renders_total{device="iphone-15",style="dots",bg="ios",lang="en",weekends="off"} 1` + "\n",
+		`wallpaper_render_duration_seconds_sum{device="iphone-15",bg="ios"} 0.04` + "\n",
+		`wallpaper_encoded_bytes_bucket{device="iphone-15",format="png",le="65536"} 0` + "\n",
+		`wallpaper_encoded_bytes_bucket{device="iphone-15",format="png",le="131072"} 1` + "\n",
+		"# TYPE font_cache_hits_total counter\n",
+		"# TYPE font_cache_hit_ratio gauge\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape is missing %q", want)
+		}
+	}
+
+	// Every family has exactly one HELP and one TYPE line, and every sample
+	// belongs to the family declared before it.
+	var family string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			family = strings.Fields(line)[2]
+			if seen[family] {
+				t.Errorf("family %s declared twice", family)
+			}
+			seen[family] = true
+		case strings.HasPrefix(line, "# TYPE "):
+			if strings.Fields(line)[2] != family {
+				t.Errorf("TYPE line %q does not follow its HELP line", line)
+			}
+		case !strings.HasPrefix(line, family):
+			t.Errorf("sample %q outside family %s", line, family)
+		}
+	}
+}
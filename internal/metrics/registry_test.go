@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounterVec("requests_total", "Requests by path.\nSecond line with a \\ backslash.", "path", "code")
+	c.Inc("/b", "200")
+	c.Add(2.5, `/a "quoted"`+"\n"+`back\slash`, "500")
+	c.Inc("/b", "200")
+
+	h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1, 0.5}, "route")
+	for _, v := range []float64{0.05, 0.1, 0.3, 0.7, 3} {
+		h.Observe(v, "/x")
+	}
+	h.Observe(0.2, "/a")
+
+	r.NewGaugeFunc("temperature", "Current temperature.", func() float64 { return -1.5 })
+	r.NewCounterFunc("infinite_total", "Unbounded.", func() float64 { return math.Inf(1) })
+
+	want := `# HELP requests_total Requests by path.\nSecond line with a \\ backslash.
+# TYPE requests_total counter
+requests_total{path="/a \"quoted\"\nback\\slash",code="500"} 2.5
+requests_total{path="/b",code="200"} 2
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 0
+latency_seconds_bucket{route="/a",le="0.5"} 1
+latency_seconds_bucket{route="/a",le="1"} 1
+latency_seconds_bucket{route="/a",le="+Inf"} 1
+latency_seconds_sum{route="/a"} 0.2
+latency_seconds_count{route="/a"} 1
+latency_seconds_bucket{route="/x",le="0.1"} 2
+latency_seconds_bucket{route="/x",le="0.5"} 3
+latency_seconds_bucket{route="/x",le="1"} 4
+latency_seconds_bucket{route="/x",le="+Inf"} 5
+latency_seconds_sum{route="/x"} 4.15
+latency_seconds_count{route="/x"} 5
+# HELP temperature Current temperature.
+# TYPE temperature gauge
+temperature -1.5
+# HELP infinite_total Unbounded.
+# TYPE infinite_total counter
+infinite_total +Inf
+`
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := rec.Body.String(); got != want {
+		t.Errorf("exposition differs:\n got:\n%s\nwant:\n%s", got, want)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("size_bytes", "Sizes.", []float64{10})
+	h.Observe(10)
+	h.Observe(11)
+
+	var b strings.Builder
+	r.WriteText(&b)
+	want := `# HELP size_bytes Sizes.
+# TYPE size_bytes histogram
+size_bytes_bucket{le="10"} 1
+size_bytes_bucket{le="+Inf"} 2
+size_bytes_sum 21
+size_bytes_count 2
+`
+	if got := b.String(); got != want {
+		t.Errorf("exposition differs:\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestLimiterBurstThenRefill(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLimiter(2, 3, clock)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within burst was rejected", i)
+		}
+	}
+
+	ok, wait := l.Allow("a")
+	if ok {
+		t.Fatal("request over burst was allowed")
+	}
+	if wait != 500*time.Millisecond {
+		t.Fatalf("retry after = %v, want 500ms", wait)
+	}
+
+	clock.Advance(499 * time.Millisecond)
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("request allowed before a token was refilled")
+	}
+
+	clock.Advance(time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("request rejected after a token was refilled")
+	}
+}
+
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := NewLimiter(1, 1, clock)
+
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("first request for a was rejected")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("second request for a was allowed")
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatal("first request for b was rejected")
+	}
+}
+
+func TestLimiterRefillCapsAtBurst(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := NewLimiter(10, 2, clock)
+
+	l.Allow("a")
+	clock.Advance(time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d after idle period was rejected", i)
+		}
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("idle period refilled more than burst")
+	}
+}
+
+func TestLimiterSweepsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := NewLimiter(1, 1, clock)
+
+	for i := 0; i < sweepEvery-1; i++ {
+		l.Allow(strconv.Itoa(i))
+	}
+	clock.Advance(time.Minute)
+	l.Allow("fresh")
+
+	if n := l.Len(); n != 1 {
+		t.Fatalf("buckets after sweep = %d, want 1", n)
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	s := NewSemaphore(1)
+
+	if !s.TryAcquire() {
+		t.Fatal("first acquire failed")
+	}
+	if s.TryAcquire() {
+		t.Fatal("acquire beyond capacity succeeded")
+	}
+	if s.Acquire(context.Background(), 10*time.Millisecond) {
+		t.Fatal("acquire with timeout succeeded while full")
+	}
+
+	s.Release()
+	if !s.Acquire(context.Background(), 0) {
+		t.Fatal("acquire after release failed")
+	}
+	if s.InUse() != 1 {
+		t.Fatalf("in use = %d, want 1", s.InUse())
+	}
+}
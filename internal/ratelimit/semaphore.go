@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+type Semaphore struct {
+	slots chan struct{}
+}
+
+func NewSemaphore(n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Semaphore) Acquire(ctx context.Context, wait time.Duration) bool {
+	if s.TryAcquire() {
+		return true
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Semaphore) Release() {
+	<-s.slots
+}
+
+func (s *Semaphore) InUse() int {
+	return len(s.slots)
+}
+
+func (s *Semaphore) Capacity() int {
+	return cap(s.slots)
+}
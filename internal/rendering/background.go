@@ -68,11 +68,12 @@ func parseHexColor(s string) color.RGBA {
 		return color.RGBA{0, 0, 0, 255}
 	}
 
-	r, _ := strconv.ParseUint(s[0:2], 16, 8)
-	g, _ := strconv.ParseUint(s[2:4], 16, 8)
-	b, _ := strconv.ParseUint(s[4:6], 16, 8)
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{0, 0, 0, 255}
+	}
 
-	return color.RGBA{uint8(r), uint8(g), uint8(b), 255}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}
 }
 
 func fillSolid(img *image.RGBA, base color.RGBA) {
@@ -106,16 +107,22 @@ func drawGradientWithBase(img *image.RGBA, base color.RGBA) {
 	addVignette(img, 0.45)
 }
 
+// noiseSeed gives every render its own grain; golden tests replace it to
+// get reproducible images.
+var noiseSeed = func(w, h int) int64 {
+	return time.Now().UnixNano()
+}
+
 func drawNoiseWithBase(img *image.RGBA, base color.RGBA) {
 	fillSolid(img, base)
-	rand.Seed(time.Now().UnixNano())
 
 	w := img.Bounds().Dx()
 	h := img.Bounds().Dy()
+	rng := rand.New(rand.NewSource(noiseSeed(w, h)))
 
 	for y := 0; y < h; y++ {
 		for x := 0; x < w; x++ {
-			n := rand.Intn(16) - 8
+			n := rng.Intn(16) - 8
 			c := img.RGBAAt(x, y)
 			img.Set(x, y, color.RGBA{
 				uint8(clamp(int(c.R)+n, 0, 255)),
@@ -0,0 +1,86 @@
+package rendering
+
+import (
+	"fmt"
+	"image/color"
+	"net/url"
+	"testing"
+)
+
+var black = color.RGBA{0, 0, 0, 255}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+	}{
+		{"#ff8000", color.RGBA{255, 128, 0, 255}},
+		{"#000000", black},
+		{"#ABCDEF", color.RGBA{171, 205, 239, 255}},
+		{"#fff", black},
+		{"#12zz56", black},
+		{"#+12345", black},
+		{"#_12345", black},
+		{"#1234567", black},
+		{"", black},
+	}
+	for _, tt := range tests {
+		if got := parseHexColor(tt.in); got != tt.want {
+			t.Errorf("parseHexColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBackgroundBaseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+	}{
+		{"", black},
+		{"black", black},
+		{"blue", color.RGBA{10, 20, 40, 255}},
+		{" Purple ", color.RGBA{25, 10, 40, 255}},
+		{"%23102030", color.RGBA{16, 32, 48, 255}},
+		{"#102030", color.RGBA{16, 32, 48, 255}},
+		{"%zz", black},
+		{"unknown", black},
+	}
+	for _, tt := range tests {
+		if got := backgroundBaseColor(tt.in); got != tt.want {
+			t.Errorf("backgroundBaseColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func FuzzParseHexColor(f *testing.F) {
+	for _, seed := range []string{"#ff8000", "#fff", "#12zz56", "#-12345", "#ééé", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		c := parseHexColor(s)
+		if c.A != 255 {
+			t.Fatalf("parseHexColor(%q) alpha = %d", s, c.A)
+		}
+		if c != black {
+			canonical := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+			if got := parseHexColor(canonical); got != c {
+				t.Fatalf("round trip of %q via %q = %v, want %v", s, canonical, got, c)
+			}
+		}
+	})
+}
+
+func FuzzBackgroundBaseColor(f *testing.F) {
+	for _, seed := range []string{"", "blue", "%23ff0000", "#00ff00", "%", "+#ffffff+"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		c := backgroundBaseColor(s)
+		if c.A != 255 {
+			t.Fatalf("backgroundBaseColor(%q) alpha = %d", s, c.A)
+		}
+		if got := backgroundBaseColor(url.QueryEscape(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))); got != c {
+			t.Fatalf("escaped hex of %v parsed as %v", c, got)
+		}
+	})
+}
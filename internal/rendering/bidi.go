@@ -0,0 +1,256 @@
+package rendering
+
+import (
+	"slices"
+	"unicode"
+)
+
+// visualText turns logical text into the left-to-right glyph order that
+// font.Drawer expects: Arabic letters take their contextual forms and
+// right-to-left runs are reordered. It is a small subset of the Unicode
+// bidi algorithm, enough for dates, month names and the footer.
+func visualText(s string) string {
+	runes := []rune(s)
+	if !slices.ContainsFunc(runes, isRTL) {
+		return s
+	}
+	return string(reorderRuns(shapeArabic(runes)))
+}
+
+func isRTL(r rune) bool {
+	return unicode.In(r, unicode.Hebrew, unicode.Arabic)
+}
+
+type bidiClass int
+
+const (
+	bidiNeutral bidiClass = iota
+	bidiLTR
+	bidiRTL
+)
+
+func classify(runes []rune) []bidiClass {
+	classes := make([]bidiClass, len(runes))
+	for i, r := range runes {
+		switch {
+		case isRTL(r) && !unicode.IsDigit(r):
+			classes[i] = bidiRTL
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			classes[i] = bidiLTR
+		}
+	}
+
+	// Signs attached to a number travel with it: "80%", "-5", "1.5".
+	for i, r := range runes {
+		if classes[i] != bidiNeutral || !isNumberSign(r) {
+			continue
+		}
+		prevDigit := i > 0 && unicode.IsDigit(runes[i-1])
+		nextDigit := i+1 < len(runes) && unicode.IsDigit(runes[i+1])
+		if prevDigit || nextDigit {
+			classes[i] = bidiLTR
+		}
+	}
+	return classes
+}
+
+func isNumberSign(r rune) bool {
+	switch r {
+	case '%', '٪', '‰', '+', '-', '.', ',', ':', '/':
+		return true
+	}
+	return unicode.Is(unicode.Sc, r)
+}
+
+func reorderRuns(runes []rune) []rune {
+	classes := classify(runes)
+
+	base := bidiLTR
+	if i := slices.IndexFunc(classes, func(c bidiClass) bool { return c != bidiNeutral }); i >= 0 {
+		base = classes[i]
+	}
+
+	// Neutrals between two runs of the same direction join them;
+	// otherwise they follow the paragraph direction.
+	for i := 0; i < len(classes); {
+		if classes[i] != bidiNeutral {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) && classes[j] == bidiNeutral {
+			j++
+		}
+		dir := base
+		if i > 0 && j < len(classes) && classes[i-1] == classes[j] {
+			dir = classes[j]
+		}
+		for k := i; k < j; k++ {
+			classes[k] = dir
+		}
+		i = j
+	}
+
+	type run struct{ start, end int }
+	var runs []run
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && classes[j] == classes[i] {
+			j++
+		}
+		runs = append(runs, run{i, j})
+		i = j
+	}
+	if base == bidiRTL {
+		slices.Reverse(runs)
+	}
+
+	out := make([]rune, 0, len(runes))
+	for _, rn := range runs {
+		seg := runes[rn.start:rn.end]
+		if classes[rn.start] != bidiRTL {
+			out = append(out, seg...)
+			continue
+		}
+		for k := len(seg) - 1; k >= 0; k-- {
+			out = append(out, mirrorRune(seg[k]))
+		}
+	}
+	return out
+}
+
+func mirrorRune(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case ')':
+		return '('
+	case '[':
+		return ']'
+	case ']':
+		return '['
+	case '«':
+		return '»'
+	case '»':
+		return '«'
+	}
+	return r
+}
+
+// Presentation forms B, in isolated, final, initial, medial order.
+// Right-joining letters only have the first two.
+var arabicForms = map[rune][]rune{
+	'ء': {0xFE80},
+	'آ': {0xFE81, 0xFE82},
+	'أ': {0xFE83, 0xFE84},
+	'ؤ': {0xFE85, 0xFE86},
+	'إ': {0xFE87, 0xFE88},
+	'ئ': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'ا': {0xFE8D, 0xFE8E},
+	'ب': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'ة': {0xFE93, 0xFE94},
+	'ت': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'ث': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'ج': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'ح': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'خ': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'د': {0xFEA9, 0xFEAA},
+	'ذ': {0xFEAB, 0xFEAC},
+	'ر': {0xFEAD, 0xFEAE},
+	'ز': {0xFEAF, 0xFEB0},
+	'س': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'ش': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'ص': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'ض': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'ط': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'ظ': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'ع': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'غ': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'ف': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'ق': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'ك': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'ل': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'م': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'ن': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'ه': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'و': {0xFEED, 0xFEEE},
+	'ى': {0xFEEF, 0xFEF0},
+	'ي': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+}
+
+// Lam followed by an alef variant becomes one ligature (isolated, final).
+var lamAlef = map[rune][2]rune{
+	'آ': {0xFEF5, 0xFEF6},
+	'أ': {0xFEF7, 0xFEF8},
+	'إ': {0xFEF9, 0xFEFA},
+	'ا': {0xFEFB, 0xFEFC},
+}
+
+const (
+	arabicLam     = 'ل'
+	arabicTatweel = 'ـ'
+)
+
+// Harakat and other marks don't break joining.
+func isTransparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+func joinsForward(r rune) bool {
+	return r == arabicTatweel || len(arabicForms[r]) == 4
+}
+
+func joinsBackward(r rune) bool {
+	return r == arabicTatweel || len(arabicForms[r]) >= 2
+}
+
+func shapeArabic(runes []rune) []rune {
+	neighbour := func(i, step int) rune {
+		for j := i + step; j >= 0 && j < len(runes); j += step {
+			if !isTransparent(runes[j]) {
+				return runes[j]
+			}
+		}
+		return 0
+	}
+
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		forms, ok := arabicForms[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+
+		prev := joinsForward(neighbour(i, -1))
+		next := neighbour(i, 1)
+
+		if r == arabicLam {
+			if lig, ok := lamAlef[next]; ok {
+				if prev {
+					out = append(out, lig[1])
+				} else {
+					out = append(out, lig[0])
+				}
+				// Keep any marks between lam and alef, drop the alef.
+				for i++; isTransparent(runes[i]); i++ {
+					out = append(out, runes[i])
+				}
+				continue
+			}
+		}
+
+		form := 0
+		switch {
+		case len(forms) == 4 && prev && joinsBackward(next):
+			form = 3
+		case len(forms) == 4 && joinsBackward(next):
+			form = 2
+		case len(forms) >= 2 && prev:
+			form = 1
+		}
+		out = append(out, forms[form])
+	}
+	return out
+}
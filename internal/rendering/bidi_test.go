@@ -0,0 +1,29 @@
+package rendering
+
+import "testing"
+
+func TestVisualText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"latin untouched", "300 d left   82%", "300 d left   82%"},
+		{"hebrew word", "מרץ", "ץרמ"},
+		{"hebrew with number", "נותרו 5 ימים", "םימי 5 ורתונ"},
+		{"percent stays with number", "נותרו 80%", "80% ורתונ"},
+		{"brackets mirror", "(א)", "(א)"},
+		{"arabic joining", "بقي", "ﻲﻘﺑ"},
+		{"right-joining letter breaks word", "يوم", "ﻡﻮﻳ"},
+		{"lam alef ligature", "سلام", "ﻡﻼﺳ"},
+		{"isolated lam alef", "لا", "ﻻ"},
+		{"harakat are transparent", "بَب", "ﺐَﺑ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visualText(tt.in); got != tt.want {
+				t.Errorf("visualText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package rendering
+
+// SeedNoiseFromSize makes noise backgrounds depend only on the image size
+// until the returned function restores the time-based seed.
+func SeedNoiseFromSize() (restore func()) {
+	prev := noiseSeed
+	noiseSeed = func(w, h int) int64 { return int64(w)<<32 | int64(h) }
+	return func() { noiseSeed = prev }
+}
@@ -0,0 +1,105 @@
+package rendering
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/image/font"
+)
+
+// Faces are cached per font and size; a render uses up to five.
+const maxFontCacheEntries = 256
+
+type FontCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// Counters are shared by every registry so metrics don't need a handle
+// on one.
+var fontCacheStats struct {
+	hits, misses atomic.Uint64
+	entries      atomic.Int64
+}
+
+func ReadFontCacheStats() FontCacheStats {
+	return FontCacheStats{
+		Hits:    fontCacheStats.hits.Load(),
+		Misses:  fontCacheStats.misses.Load(),
+		Entries: int(fontCacheStats.entries.Load()),
+	}
+}
+
+type faceEntry struct {
+	key  string
+	done chan struct{}
+	face font.Face
+}
+
+// faceCache is an LRU of faces. The lock only guards the index: a face is
+// built outside it, and concurrent requests for the same key wait for the
+// first build instead of repeating it.
+type faceCache struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+}
+
+func (c *faceCache) get(key string, build func() font.Face) font.Face {
+	c.mu.Lock()
+	if c.items == nil {
+		c.items = make(map[string]*list.Element)
+		c.order = list.New()
+	}
+
+	if el, ok := c.items[key]; ok {
+		fontCacheStats.hits.Add(1)
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+
+		e := el.Value.(*faceEntry)
+		<-e.done
+		if e.face == nil {
+			panic("font face " + key + " failed to build")
+		}
+		return e.face
+	}
+	fontCacheStats.misses.Add(1)
+
+	e := &faceEntry{key: key, done: make(chan struct{})}
+	c.items[key] = c.order.PushFront(e)
+	fontCacheStats.entries.Add(1)
+	c.evict()
+	c.mu.Unlock()
+
+	defer func() {
+		if e.face == nil {
+			c.remove(e)
+		}
+		close(e.done)
+	}()
+	e.face = build()
+	return e.face
+}
+
+func (c *faceCache) evict() {
+	for c.order.Len() > maxFontCacheEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*faceEntry).key)
+		fontCacheStats.entries.Add(-1)
+	}
+}
+
+func (c *faceCache) remove(e *faceEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[e.key]; ok && el.Value == e {
+		c.order.Remove(el)
+		delete(c.items, e.key)
+		fontCacheStats.entries.Add(-1)
+	}
+}
@@ -0,0 +1,190 @@
+package rendering
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/domain"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+type script int
+
+const (
+	scriptOther script = iota
+	scriptArabic
+	scriptHebrew
+	scriptHan
+	scriptKana
+	scriptHangul
+)
+
+// Fonts tried, in order, for runes the primary font has no glyph for.
+// They live in assets.FallbackFontDir; missing files are skipped so a
+// deployment only needs the scripts it serves. The DejaVu subset is
+// bundled, so Arabic and Hebrew always render.
+var fallbackFontFiles = map[script][]string{
+	scriptArabic: {"NotoSansArabic-Bold.ttf", bundledFallbackFont},
+	scriptHebrew: {"NotoSansHebrew-Bold.ttf", bundledFallbackFont},
+	scriptHan:    {"NotoSansSC-Bold.otf", "NotoSansJP-Bold.otf", "NotoSansKR-Bold.otf"},
+	scriptKana:   {"NotoSansJP-Bold.otf"},
+	scriptHangul: {"NotoSansKR-Bold.otf"},
+}
+
+// bundledFallbackFont is DejaVu Sans Condensed Bold cut down to the Hebrew
+// alphabet and the Arabic letters, digits and presentation forms that
+// shapeArabic produces; see the README in assets.FallbackFontDir.
+const bundledFallbackFont = "DejaVuSansCondensed-Bold-Subset.ttf"
+
+func scriptOf(r rune) script {
+	switch {
+	case unicode.Is(unicode.Arabic, r):
+		return scriptArabic
+	case unicode.Is(unicode.Hebrew, r):
+		return scriptHebrew
+	case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+		return scriptKana
+	case unicode.Is(unicode.Hangul, r):
+		return scriptHangul
+	case unicode.Is(unicode.Han, r):
+		return scriptHan
+	default:
+		return scriptOther
+	}
+}
+
+type fallbackFonts map[script][]*opentype.Font
+
+func loadFallbackFonts(fsys fs.FS) (fallbackFonts, error) {
+	parsed := make(map[string]*opentype.Font)
+	fonts := make(fallbackFonts)
+
+	for s, files := range fallbackFontFiles {
+		for _, name := range files {
+			f, seen := parsed[name]
+			if !seen {
+				b, err := fs.ReadFile(fsys, path.Join(assets.FallbackFontDir, name))
+				if errors.Is(err, fs.ErrNotExist) {
+					parsed[name] = nil
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("fallback font %s: %w", name, err)
+				}
+				if f, err = opentype.Parse(b); err != nil {
+					return nil, fmt.Errorf("fallback font %s: %w", name, err)
+				}
+				parsed[name] = f
+			}
+			if f != nil {
+				fonts[s] = append(fonts[s], f)
+			}
+		}
+	}
+	return fonts, nil
+}
+
+// Uncovered lists the languages whose month and weekday names the default
+// font and the installed fallback fonts cannot draw; they render as boxes.
+func (r *FontRegistry) Uncovered() []string {
+	face := r.Face(domain.FontSpec{}, 16)
+
+	var langs []string
+	for _, lang := range domain.Languages() {
+		m := domain.MessagesFor(lang)
+		text := strings.Join(m.MonthsLong[:], "") + strings.Join(m.Weekdays[:], "") + strings.Join(m.WeekdayInitials[:], "")
+		for _, c := range text {
+			if _, ok := face.GlyphAdvance(c); !ok && unicode.IsLetter(c) {
+				langs = append(langs, lang)
+				break
+			}
+		}
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// newFace returns a plain face when no fallback fonts are installed.
+func newFace(primary *opentype.Font, fallbacks fallbackFonts, size float64) font.Face {
+	face := mustFace(primary, size)
+	if len(fallbacks) == 0 {
+		return face
+	}
+
+	faces := make(map[*opentype.Font]font.Face)
+	ff := &fallbackFace{primary: face, scripts: make(map[script][]font.Face)}
+	for s, fonts := range fallbacks {
+		for _, f := range fonts {
+			if faces[f] == nil {
+				faces[f] = mustFace(f, size)
+				ff.all = append(ff.all, faces[f])
+			}
+			ff.scripts[s] = append(ff.scripts[s], faces[f])
+		}
+	}
+	return ff
+}
+
+// fallbackFace draws each rune with the first face that has a glyph for
+// it: the primary font, then the fonts for the rune's script, then any
+// other fallback font.
+type fallbackFace struct {
+	primary font.Face
+	scripts map[script][]font.Face
+	all     []font.Face
+}
+
+func (f *fallbackFace) faceFor(r rune) font.Face {
+	if _, ok := f.primary.GlyphAdvance(r); ok {
+		return f.primary
+	}
+	for _, face := range f.scripts[scriptOf(r)] {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+	for _, face := range f.all {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+	return f.primary
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.faceFor(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphAdvance(r)
+}
+
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.faceFor(r0)
+	if face != f.faceFor(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.primary.Metrics()
+}
+
+func (f *fallbackFace) Close() error {
+	return nil
+}
@@ -0,0 +1,219 @@
+package rendering
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/domain"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestFallbackFace(t *testing.T) {
+	registry, err := LoadFonts(os.DirFS("../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := registry.families[domain.DefaultFontFamily][0].font
+	fallback := mustParseFont(gobold.TTF)
+
+	if _, ok := newFace(primary, nil, 20).(*fallbackFace); ok {
+		t.Fatal("newFace without fallbacks returned a fallbackFace")
+	}
+
+	face := newFace(primary, fallbackFonts{scriptOther: {fallback}}, 20)
+	plain := mustFace(primary, 20)
+	other := mustFace(fallback, 20)
+
+	// SF Pro has no box-drawing glyphs; Go Bold does.
+	const missing = '╬'
+	if _, ok := plain.GlyphAdvance(missing); ok {
+		t.Fatalf("primary font unexpectedly has %q", missing)
+	}
+
+	for _, r := range []rune{'A', '7', missing} {
+		want, _ := plain.GlyphAdvance(r)
+		if r == missing {
+			want, _ = other.GlyphAdvance(r)
+		}
+		got, ok := face.GlyphAdvance(r)
+		if !ok || got != want {
+			t.Errorf("GlyphAdvance(%q) = %v, %v; want %v, true", r, got, ok, want)
+		}
+	}
+
+	if k := face.Kern('A', missing); k != 0 {
+		t.Errorf("Kern across faces = %v, want 0", k)
+	}
+	if face.Metrics() != plain.Metrics() {
+		t.Error("Metrics should come from the primary face")
+	}
+}
+
+func TestFallbackFaceScripts(t *testing.T) {
+	registry, err := LoadFonts(os.DirFS("../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := registry.families[domain.DefaultFontFamily][0].font
+
+	ar := domain.MessagesFor("ar")
+	arabic := ar.MonthsLong[0]
+	han := domain.MessagesFor("zh").MonthsLong[0]
+	labels := strings.Join(ar.MonthsLong[:], "") + strings.Join(ar.Weekdays[:], "") + strings.Join(ar.WeekdayInitials[:], "")
+	stub := scriptStubFont(t, labels+han)
+
+	plain := newFace(primary, nil, 20)
+	face := newFace(primary, fallbackFonts{scriptArabic: {stub}, scriptHan: {stub}}, 20)
+	for _, label := range []string{arabic, han} {
+		for _, r := range label {
+			if _, ok := plain.GlyphAdvance(r); ok {
+				t.Fatalf("primary font unexpectedly has %q", r)
+			}
+			if _, ok := face.GlyphAdvance(r); !ok {
+				t.Errorf("%q: no glyph with the fallback installed", r)
+			}
+		}
+
+		img := image.NewRGBA(image.Rect(0, 0, 200, 40))
+		drawText(img, label, 100, 30, color.White, face)
+		if !slices.ContainsFunc(img.Pix, func(b uint8) bool { return b != 0 }) {
+			t.Errorf("%q drew nothing", label)
+		}
+	}
+
+	registry.fallbacks = fallbackFonts{scriptArabic: {stub}}
+	registry.faces = faceCache{}
+	if got := registry.Uncovered(); slices.Contains(got, "ar") || !slices.Contains(got, "zh") {
+		t.Errorf("Uncovered() with an Arabic fallback = %v", got)
+	}
+}
+
+func TestUncovered(t *testing.T) {
+	registry, err := LoadFonts(os.DirFS("../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.fallbacks[scriptHan]) > 0 {
+		t.Skip("CJK fallback fonts are installed")
+	}
+	want := []string{"ja", "ko", "zh"}
+	if got := registry.Uncovered(); !slices.Equal(got, want) {
+		t.Errorf("Uncovered() = %v, want %v", got, want)
+	}
+}
+
+func TestBundledFallbackCoversRTL(t *testing.T) {
+	fsys := os.DirFS("../..")
+	data, err := fs.ReadFile(fsys, path.Join(assets.FallbackFontDir, bundledFallbackFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subset, err := sfnt.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chars, err := fs.ReadFile(fsys, path.Join(assets.FallbackFontDir, "subset-chars.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range strings.TrimSpace(string(chars)) {
+		if g, _ := subset.GlyphIndex(nil, r); g == 0 {
+			t.Errorf("%s has no glyph for %U listed in subset-chars.txt", bundledFallbackFont, r)
+		}
+	}
+
+	registry, err := LoadFonts(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := registry.Face(domain.FontSpec{}, 16)
+	for _, lang := range []string{"ar", "he"} {
+		m := domain.MessagesFor(lang)
+		texts := slices.Concat(m.MonthsShort[:], m.MonthsLong[:], m.Weekdays[:], m.WeekdayInitials[:],
+			[]string{m.DateFormat, m.PercentFormat, m.GroupSeparator, m.Digits})
+		for _, text := range m.DaysLeft {
+			texts = append(texts, text)
+		}
+		for _, text := range texts {
+			for _, r := range visualText(text) {
+				if _, ok := face.GlyphAdvance(r); !ok && !unicode.IsSpace(r) {
+					t.Errorf("%s: no glyph for %U in %q", lang, r, text)
+				}
+			}
+		}
+	}
+}
+
+// scriptStubFont stands in for a Noto font: Go Bold with a cmap that maps
+// each rune in runes to Go Bold's 'W', so the tests need no CJK or Arabic
+// font files.
+func scriptStubFont(t *testing.T, runes string) *opentype.Font {
+	t.Helper()
+
+	base, err := sfnt.Parse(gobold.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	glyph, err := base.GlyphIndex(nil, 'W')
+	if err != nil || glyph == 0 {
+		t.Fatalf("Go Bold has no W: %v", err)
+	}
+
+	var rs []rune
+	for _, r := range runes {
+		rs = append(rs, r)
+	}
+	slices.Sort(rs)
+	rs = slices.Compact(rs)
+
+	// cmap with one format 12 subtable (Windows, UCS-4): one group per rune.
+	be := binary.BigEndian
+	cmap := be.AppendUint16(nil, 0)
+	cmap = be.AppendUint16(cmap, 1)
+	cmap = be.AppendUint16(cmap, 3)
+	cmap = be.AppendUint16(cmap, 10)
+	cmap = be.AppendUint32(cmap, 12)
+	cmap = be.AppendUint16(cmap, 12)
+	cmap = be.AppendUint16(cmap, 0)
+	cmap = be.AppendUint32(cmap, uint32(16+12*len(rs)))
+	cmap = be.AppendUint32(cmap, 0)
+	cmap = be.AppendUint32(cmap, uint32(len(rs)))
+	for _, r := range rs {
+		cmap = be.AppendUint32(cmap, uint32(r))
+		cmap = be.AppendUint32(cmap, uint32(r))
+		cmap = be.AppendUint32(cmap, uint32(glyph))
+	}
+
+	// Append it and point the table directory's cmap record at it.
+	data := slices.Clone(gobold.TTF)
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	offset := len(data)
+	data = append(data, cmap...)
+	found := false
+	for i, n := 0, int(be.Uint16(data[4:])); i < n; i++ {
+		rec := data[12+16*i:]
+		if string(rec[:4]) == "cmap" {
+			be.PutUint32(rec[8:], uint32(offset))
+			be.PutUint32(rec[12:], uint32(len(cmap)))
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Go Bold has no cmap table")
+	}
+	return mustParseFont(data)
+}
@@ -0,0 +1,159 @@
+package rendering
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/domain"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// fontFile is one weight of a family, either compiled in (data) or read
+// from the assets FS (path).
+type fontFile struct {
+	weight domain.FontWeight
+	path   string
+	data   []byte
+}
+
+// Every family here ships with the repository. Only the default font is
+// required; the others are skipped when their files are missing.
+var fontFamilies = map[string][]fontFile{
+	domain.DefaultFontFamily: {
+		{weight: domain.FontWeightBold, path: assets.FontPath},
+	},
+	"go": {
+		{weight: domain.FontWeightRegular, data: goregular.TTF},
+		{weight: domain.FontWeightMedium, data: gomedium.TTF},
+		{weight: domain.FontWeightBold, data: gobold.TTF},
+	},
+	"go-mono": {
+		{weight: domain.FontWeightRegular, data: gomono.TTF},
+		{weight: domain.FontWeightBold, data: gomonobold.TTF},
+	},
+	"dejavu-serif": {
+		{weight: domain.FontWeightRegular, path: "fonts/DejaVuSerif.ttf"},
+		{weight: domain.FontWeightBold, path: "fonts/DejaVuSerif-Bold.ttf"},
+	},
+}
+
+// The wallpaper has always been drawn in bold.
+const defaultFontWeight = domain.FontWeightBold
+
+type loadedFont struct {
+	weight domain.FontWeight
+	font   *opentype.Font
+}
+
+// FontRegistry holds every installed font, parsed once, and builds faces
+// from them on demand. It is safe for concurrent use.
+type FontRegistry struct {
+	families  map[string][]loadedFont
+	fallbacks fallbackFonts
+	faces     faceCache
+}
+
+// LoadFonts parses all installed fonts. Optional families whose files are
+// missing are skipped; the default font must be present.
+func LoadFonts(fsys fs.FS) (*FontRegistry, error) {
+	r := &FontRegistry{families: make(map[string][]loadedFont)}
+
+	for name, files := range fontFamilies {
+		for _, f := range files {
+			data := f.data
+			if data == nil {
+				b, err := fs.ReadFile(fsys, f.path)
+				if errors.Is(err, fs.ErrNotExist) && name != domain.DefaultFontFamily {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("font %s: %w", f.path, err)
+				}
+				data = b
+			}
+			parsed, err := opentype.Parse(data)
+			if err != nil {
+				return nil, fmt.Errorf("font %s %d: %w", name, f.weight, err)
+			}
+			r.families[name] = append(r.families[name], loadedFont{weight: f.weight, font: parsed})
+		}
+	}
+
+	fallbacks, err := loadFallbackFonts(fsys)
+	if err != nil {
+		return nil, err
+	}
+	r.fallbacks = fallbacks
+	return r, nil
+}
+
+// Families lists the installed font families.
+func (r *FontRegistry) Families() []string {
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// resolve picks the installed weight closest to the requested one,
+// preferring the heavier on a tie. Unknown families fall back to the
+// default font.
+func (r *FontRegistry) resolve(spec domain.FontSpec) (string, loadedFont) {
+	family := spec.Family
+	fonts, ok := r.families[family]
+	if !ok {
+		family = domain.DefaultFontFamily
+		fonts = r.families[family]
+	}
+
+	want := spec.Weight
+	if want == 0 {
+		want = defaultFontWeight
+	}
+
+	best := fonts[0]
+	for _, f := range fonts[1:] {
+		d, bd := absWeight(f.weight-want), absWeight(best.weight-want)
+		if d < bd || d == bd && f.weight > best.weight {
+			best = f
+		}
+	}
+	return family, best
+}
+
+func absWeight(w domain.FontWeight) domain.FontWeight {
+	if w < 0 {
+		return -w
+	}
+	return w
+}
+
+func (r *FontRegistry) Face(spec domain.FontSpec, size float64) font.Face {
+	family, f := r.resolve(spec)
+	key := fmt.Sprintf("%s:%d@%.4f", family, f.weight, size)
+	return r.faces.get(key, func() font.Face {
+		return newFace(f.font, r.fallbacks, size)
+	})
+}
+
+func (r *FontRegistry) FontSet(scale float64, fonts domain.FontChoice) FontSet {
+	return FontSet{
+		Month:  r.Face(fonts.Title, BaseMonthFont*scale*MonthTitleScale),
+		Footer: r.Face(fonts.Footer, BaseFooterFont*scale*FooterScale),
+		Number: r.Face(fonts.Numbers, BaseNumberFont*scale*DayGridScale),
+		Clock:  r.Face(domain.FontSpec{}, BaseClockFont*scale),
+		Date:   r.Face(domain.FontSpec{}, BaseDateFont*scale),
+	}
+}
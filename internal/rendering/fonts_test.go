@@ -0,0 +1,138 @@
+package rendering
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/domain"
+
+	"golang.org/x/image/font"
+)
+
+func loadTestFonts(t *testing.T) *FontRegistry {
+	t.Helper()
+	fonts, err := LoadFonts(os.DirFS("../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fonts
+}
+
+func TestResolveFont(t *testing.T) {
+	fonts := loadTestFonts(t)
+
+	tests := []struct {
+		spec       domain.FontSpec
+		wantFamily string
+		wantWeight domain.FontWeight
+	}{
+		{domain.FontSpec{}, domain.DefaultFontFamily, domain.FontWeightBold},
+		{domain.FontSpec{Family: "go"}, "go", domain.FontWeightBold},
+		{domain.FontSpec{Family: "go", Weight: 300}, "go", domain.FontWeightRegular},
+		{domain.FontSpec{Family: "go", Weight: 600}, "go", domain.FontWeightBold},
+		{domain.FontSpec{Family: "go-mono", Weight: domain.FontWeightMedium}, "go-mono", domain.FontWeightRegular},
+		{domain.FontSpec{Family: "dejavu-serif", Weight: domain.FontWeightRegular}, "dejavu-serif", domain.FontWeightRegular},
+		{domain.FontSpec{Family: "sf-pro", Weight: domain.FontWeightRegular}, domain.DefaultFontFamily, domain.FontWeightBold},
+		{domain.FontSpec{Family: "comic-sans"}, domain.DefaultFontFamily, domain.FontWeightBold},
+	}
+	for _, tt := range tests {
+		family, f := fonts.resolve(tt.spec)
+		if family != tt.wantFamily || f.weight != tt.wantWeight {
+			t.Errorf("resolve(%v) = %s:%d, want %s:%d", tt.spec, family, f.weight, tt.wantFamily, tt.wantWeight)
+		}
+	}
+}
+
+func TestFamiliesSkipsMissingFiles(t *testing.T) {
+	got := loadTestFonts(t).Families()
+	for _, want := range []string{"dejavu-serif", "go", "go-mono", domain.DefaultFontFamily} {
+		if !slices.Contains(got, want) {
+			t.Errorf("Families() = %v, missing %q", got, want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join("../..", assets.FontPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fonts, err := LoadFonts(fstest.MapFS{assets.FontPath: {Data: data}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fonts.Families(); slices.Contains(got, "dejavu-serif") {
+		t.Errorf("Families() = %v, lists dejavu-serif without its files", got)
+	}
+}
+
+func TestFontFamiliesAreBundled(t *testing.T) {
+	for name, files := range fontFamilies {
+		for _, f := range files {
+			if f.data != nil {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join("../..", f.path)); err != nil {
+				t.Errorf("family %s: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestLoadFontsErrors(t *testing.T) {
+	if _, err := LoadFonts(fstest.MapFS{}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing default font: err = %v, want ErrNotExist", err)
+	}
+
+	broken := fstest.MapFS{assets.FontPath: {Data: []byte("not a font")}}
+	if _, err := LoadFonts(broken); err == nil {
+		t.Error("broken default font: want error")
+	}
+}
+
+func TestFaceCacheBuildsOncePerKey(t *testing.T) {
+	var c faceCache
+	var builds atomic.Int32
+	release := make(chan struct{})
+	face := mustFace(mustParseFont(mustReadDefault(t)), 12)
+
+	var wg sync.WaitGroup
+	got := make([]font.Face, 8)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = c.get("k", func() font.Face {
+				builds.Add(1)
+				<-release
+				return face
+			})
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := builds.Load(); n != 1 {
+		t.Errorf("built %d times, want 1", n)
+	}
+	for i, f := range got {
+		if f != face {
+			t.Errorf("caller %d got a different face", i)
+		}
+	}
+}
+
+func mustReadDefault(t *testing.T) []byte {
+	t.Helper()
+	b, err := os.ReadFile("../../" + assets.FontPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
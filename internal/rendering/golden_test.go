@@ -0,0 +1,193 @@
+package rendering_test
+
+import (
+	"context"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/heatmapstore"
+	"calendar-wallpaper/internal/rendering"
+	"calendar-wallpaper/internal/usecase"
+)
+
+var update = flag.Bool("update", false, "regenerate golden images in testdata/golden")
+
+const (
+	goldenDir = "testdata/golden"
+	diffDir   = "testdata/diff"
+
+	// A pixel counts as changed when any channel differs by more than
+	// channelTolerance; the test fails when more than maxChangedRatio of
+	// all pixels changed.
+	channelTolerance = 8
+	maxChangedRatio  = 0.001
+)
+
+type goldenCase struct {
+	name   string
+	date   time.Time
+	params usecase.RenderParams
+}
+
+func goldenCases() []goldenCase {
+	leapDay := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+	newYear := time.Date(2027, time.January, 1, 12, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(2026, time.December, 31, 12, 0, 0, 0, time.UTC)
+
+	return []goldenCase{
+		{"iphone-15_dots_ios", leapDay, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "ios"}},
+		{"iphone-15_bars_gradient_blue", newYear, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "bars", BgStyle: "gradient", BgColor: "blue", Weekends: "blue"}},
+		{"iphone-15_numbers_plain_ru", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "numbers", BgStyle: "plain", Lang: "ru", Weekends: "red"}},
+		{"iphone-se-1_dots_noise", leapDay, usecase.RenderParams{DeviceKey: "iphone-se-1", DayStyle: "dots", BgStyle: "noise", BgColor: "green"}},
+		{"iphone-se-1_numbers_plain_small", newYear, usecase.RenderParams{DeviceKey: "iphone-se-1", DayStyle: "numbers", BgStyle: "plain", SizePercent: 80}},
+		{"iphone-16-pro-max_dots_plain_large", yearEnd, usecase.RenderParams{DeviceKey: "iphone-16-pro-max", DayStyle: "dots", BgStyle: "plain", BgColor: "#203040", SizePercent: 130, Weekends: "gray"}},
+		{"iphone-15-pro_dots_ios_preview", leapDay, usecase.RenderParams{DeviceKey: "iphone-15-pro", DayStyle: "dots", BgStyle: "ios", Preview: true}},
+		{"iphone-15_numbers_fonts", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "numbers", BgStyle: "plain", Font: "dejavu-serif", NumberFont: "go-mono", NumberWeight: "regular", FooterFont: "go", FooterWeight: "medium"}},
+		{"iphone-15_dots_footer_above", leapDay, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "plain", Footer: "Week {week} · Q{quarter} · {countdown:trip} days to trip", FooterPosition: "above", Countdowns: []string{"trip:2024-04-01"}}},
+		{"iphone-15_dots_footer_bar", newYear, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "plain", FooterStyle: "bar"}},
+		{"iphone-15_numbers_weeknums", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "numbers", BgStyle: "plain", WeekNumbers: true, WeekStart: "sun", Weekends: "blue"}},
+		{"iphone-15_dots_weekdays_ru", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "plain", Lang: "ru", Weekdays: true, Weekends: "red"}},
+		{"iphone-15_dots_weekdays_ar", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "plain", Lang: "ar", Weekdays: true, Weekends: "green"}},
+		{"iphone-15_month_ja", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", BgStyle: "plain", Mode: "month", Lang: "ja"}},
+		{"iphone-15_dots_widgets_row", newYear, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "ios", Widgets: "row", Preview: true}},
+		{"iphone-15_home_dock", leapDay, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "dots", BgStyle: "ios", Screen: "home", HomeStyle: "dock"}},
+		{"iphone-15_home_status_preview", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "bars", BgStyle: "plain", Screen: "home", HomeStyle: "status", Preview: true}},
+		{"iphone-15_home_dimmed", newYear, usecase.RenderParams{DeviceKey: "iphone-15", DayStyle: "numbers", BgStyle: "gradient", BgColor: "blue", Screen: "home", HomeStyle: "dimmed"}},
+		{"iphone-15_week", time.Date(2026, time.October, 21, 15, 40, 0, 0, time.UTC), usecase.RenderParams{DeviceKey: "iphone-15", BgStyle: "plain", Mode: "week", Weekends: "blue"}},
+		{"iphone-15_month", yearEnd, usecase.RenderParams{DeviceKey: "iphone-15", BgStyle: "plain", Mode: "month", Weekends: "blue", Events: []string{"12-25,2026-12-08"}}},
+		{"iphone-15_heatmap", time.Date(2026, time.October, 21, 12, 0, 0, 0, time.UTC), usecase.RenderParams{DeviceKey: "iphone-15", BgStyle: "plain", Mode: "heatmap", Heatmap: "runs"}},
+		{"ipad-pro-11-landscape_dots_plain", yearEnd, usecase.RenderParams{DeviceKey: "ipad-pro-11-landscape", DayStyle: "dots", BgStyle: "plain", Weekends: "blue"}},
+		{"ipad-10_dots_plain", newYear, usecase.RenderParams{DeviceKey: "ipad-10", DayStyle: "dots", BgStyle: "plain"}},
+		{"macbook-air-13_numbers_plain", leapDay, usecase.RenderParams{DeviceKey: "macbook-air-13", DayStyle: "numbers", BgStyle: "plain"}},
+		{"pixel-8_dots_preview", yearEnd, usecase.RenderParams{DeviceKey: "pixel-8", DayStyle: "dots", BgStyle: "plain", Weekends: "blue", Preview: true}},
+		{"iphone-xr_bars_ios_purple", leapDay, usecase.RenderParams{DeviceKey: "iphone-xr", DayStyle: "bars", BgStyle: "ios", BgColor: "purple", Weekends: "green"}},
+	}
+}
+
+func TestGoldenImages(t *testing.T) {
+	defer rendering.SeedNoiseFromSize()()
+
+	renderer, err := rendering.NewRenderer(os.DirFS("../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uncovered := renderer.Fonts.Uncovered()
+	for _, tc := range goldenCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.params.Lang != "" && slices.Contains(uncovered, tc.params.Lang) {
+				t.Skipf("no installed font covers %s; see fonts/fallback/README.md", tc.params.Lang)
+			}
+			service := usecase.Service{
+				Clock:    usecase.FixedClock{Time: tc.date},
+				Renderer: renderer,
+				Theme:    domain.IOSTheme(),
+				Heatmaps: heatmapstore.FileStore{Dir: "testdata/heatmaps"},
+			}
+			got, err := service.RenderWallpaper(context.Background(), tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(goldenDir, tc.name+".png")
+			if *update {
+				if err := writePNG(path, got); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := readPNG(path)
+			if err != nil {
+				t.Fatalf("read golden (run with -update to create it): %v", err)
+			}
+
+			if got.Bounds() != want.Bounds() {
+				t.Fatalf("bounds = %v, golden has %v", got.Bounds(), want.Bounds())
+			}
+
+			diff, changed := compareImages(got, want)
+			total := got.Bounds().Dx() * got.Bounds().Dy()
+			if ratio := float64(changed) / float64(total); ratio > maxChangedRatio {
+				actualPath := filepath.Join(diffDir, tc.name+".actual.png")
+				diffPath := filepath.Join(diffDir, tc.name+".diff.png")
+				_ = writePNG(actualPath, got)
+				_ = writePNG(diffPath, diff)
+				t.Fatalf("%d of %d pixels differ (%.3f%%); wrote %s and %s",
+					changed, total, ratio*100, actualPath, diffPath)
+			}
+		})
+	}
+}
+
+func compareImages(got *image.RGBA, want image.Image) (*image.RGBA, int) {
+	b := got.Bounds()
+	diff := image.NewRGBA(b)
+	changed := 0
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := got.RGBAAt(x, y)
+			w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+
+			d := maxInt(absDiff(g.R, w.R), absDiff(g.G, w.G), absDiff(g.B, w.B), absDiff(g.A, w.A))
+			if d > channelTolerance {
+				changed++
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 255, 255})
+				continue
+			}
+			gray := uint8((int(g.R) + int(g.G) + int(g.B)) / 12)
+			diff.SetRGBA(x, y, color.RGBA{gray, gray, gray, 255})
+		}
+	}
+	return diff, changed
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func maxInt(vs ...int) int {
+	m := vs[0]
+	for _, v := range vs[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,191 @@
+package rendering
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
+
+	"golang.org/x/image/font"
+)
+
+// renderHeatmap draws a year of day values as a contribution graph. Weeks
+// run down the screen when that gives bigger cells, as on phones, and
+// across it otherwise.
+func renderHeatmap(
+	ctx context.Context,
+	fonts *FontRegistry,
+	img *image.RGBA,
+	now time.Time,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	opts domain.RenderOptions,
+	scale float64,
+	faces FontSet,
+) {
+	const weeks, days = domain.HeatmapWeeks, 7
+
+	msgs := domain.MessagesFor(opts.Lang)
+	heat := domain.BuildHeatmap(now, opts.Heatmap, opts.WeekStart)
+	top, bottom, footerY := footerLayout(device, opts, scale)
+	width, height := float64(device.Width)*0.9, float64(bottom-top)
+
+	// Month names take three cells beside the weeks, weekday initials one
+	// cell beside the days and the legend one more.
+	down := int(min(width/(days+3), height/(weeks+2)))
+	across := int(min(width/(weeks+3), height/(days+2)))
+	vertical := down >= across
+	pitch := max(down, across)
+	if pitch < 4 {
+		return
+	}
+
+	labelSize := float64(pitch) * 0.6
+	monthFace := fonts.Face(opts.Fonts.Title, labelSize)
+	dayFace := fonts.Face(opts.Fonts.Numbers, labelSize)
+	capHeight := dayFace.Metrics().CapHeight.Round()
+
+	// gridX/gridY is the top-left corner of the first week's first day.
+	var gridX, gridY int
+	if vertical {
+		blockW, blockH := (days+3)*pitch, (weeks+2)*pitch
+		gridX = (device.Width-blockW)/2 + 3*pitch
+		if msgs.RTL {
+			gridX = (device.Width - blockW) / 2
+		}
+		gridY = top + (bottom-top-blockH)/2 + pitch
+	} else {
+		blockW, blockH := (weeks+3)*pitch, (days+2)*pitch
+		gridX = (device.Width-blockW)/2 + 3*pitch
+		if msgs.RTL {
+			gridX = (device.Width - blockW) / 2
+		}
+		gridY = top + (bottom-top-blockH)/2 + pitch
+	}
+
+	// cell is the centre of day d in week w.
+	cell := func(w, d int) image.Point {
+		if vertical {
+			return image.Pt(gridX+dayColumn(d, days, msgs.RTL)*pitch+pitch/2, gridY+w*pitch+pitch/2)
+		}
+		return image.Pt(gridX+dayColumn(w, weeks, msgs.RTL)*pitch+pitch/2, gridY+d*pitch+pitch/2)
+	}
+
+	base := theme
+	base.Background = backgroundBaseColor(opts.BgColor)
+	colors := base.HeatScale(heatAccent(theme, opts.HeatmapColor))
+
+	endGrid := tracing.StartSpan(ctx, "grid")
+	size := pitch * 4 / 5
+	radius := max(pitch/5, 1)
+	today := domain.DateOf(now)
+	for w := range heat.Weeks {
+		for d, c := range heat.Weeks[w] {
+			if c.Future {
+				continue
+			}
+			p := cell(w, d)
+			if domain.DateOf(c.Date) == today {
+				ring := size + 2*max(pitch/10, 1)
+				drawRoundedRect(img, squareAt(p, ring), radius+1, theme.Today)
+			}
+			drawRoundedRect(img, squareAt(p, size), radius, colors[c.Level])
+		}
+	}
+
+	// Weekday initials label the day axis, month names the weeks where
+	// a month begins.
+	initials := msgs.WeekdayHeader(opts.WeekStart.Weekday())
+	for d, initial := range initials {
+		p := cell(0, d)
+		switch {
+		case vertical:
+			p.Y -= pitch
+		case msgs.RTL:
+			p.X = gridX + weeks*pitch + pitch
+		default:
+			p.X = gridX - pitch
+		}
+		drawText(img, initial, p.X, p.Y+capHeight/2, theme.Future, dayFace)
+	}
+
+	lastLabel := -3
+	for w := range heat.Weeks {
+		month, ok := monthStart(heat.Weeks[w])
+		if !ok || w-lastLabel < 3 {
+			continue
+		}
+		lastLabel = w
+
+		name := msgs.MonthsShort[month-1]
+		p := cell(w, 0)
+		if vertical {
+			half := font.MeasureString(monthFace, name).Round() / 2
+			if msgs.RTL {
+				p.X = gridX + days*pitch + pitch/2 + half
+			} else {
+				p.X = gridX - pitch/2 - half
+			}
+		} else {
+			p.Y -= pitch
+		}
+		drawText(img, name, p.X, p.Y+capHeight/2, theme.Text, monthFace)
+	}
+
+	// The legend runs from no activity to the busiest days.
+	legendX, legendY := gridX+days*pitch/2, gridY+weeks*pitch+pitch/2
+	if !vertical {
+		legendX, legendY = gridX+weeks*pitch/2, gridY+days*pitch+pitch/2
+	}
+	for i, col := range colors {
+		x := legendX + (i-len(colors)/2)*pitch
+		drawRoundedRect(img, squareAt(image.Pt(x, legendY+pitch/4), size), radius, col)
+	}
+	endGrid()
+
+	if opts.Footer.Position == domain.FooterHidden {
+		return
+	}
+	endFooter := tracing.StartSpan(ctx, "footer")
+	drawFooter(img, now, device, theme, opts, footerY, scale, faces)
+	endFooter()
+}
+
+// monthStart returns the month whose first day falls in week, if any.
+func monthStart(week [7]domain.HeatCell) (time.Month, bool) {
+	for _, c := range week {
+		if c.Date.Day() == 1 {
+			return c.Date.Month(), true
+		}
+	}
+	return 0, false
+}
+
+// heatAccent resolves the heatmap color: a weekend color name, "today"
+// or #rrggbb, green by default like a contribution graph.
+func heatAccent(theme domain.Theme, name string) color.RGBA {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case strings.HasPrefix(name, "#"):
+		return parseHexColor(name)
+	case name == "today":
+		return theme.Today
+	case name == "gray":
+		return theme.WeekendGray
+	case name == "blue":
+		return theme.WeekendBlue
+	case name == "red":
+		return theme.WeekendRed
+	default:
+		return theme.WeekendGreen
+	}
+}
+
+func squareAt(center image.Point, size int) image.Rectangle {
+	corner := center.Sub(image.Pt(size/2, size/2))
+	return image.Rectangle{corner, corner.Add(image.Pt(size, size))}
+}
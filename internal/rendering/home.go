@@ -0,0 +1,150 @@
+package rendering
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+const (
+	homeBandHeightPt = 4
+	homeBandMarginPt = 36
+	iconSizePt       = 64
+	dimmedBlurPt     = 1.5
+	dimmedStrength   = 0.6
+)
+
+func drawHomeScreen(img *image.RGBA, now time.Time, device domain.DeviceProfile, theme domain.Theme, style domain.HomeStyle) {
+	geo := device.HomeGeometry()
+	pt := device.PointScale()
+
+	switch style {
+	case domain.HomeStatus:
+		bandH := max(int(homeBandHeightPt*pt), 1)
+		drawProgressBand(img, now, device, theme, geo.StatusBarBottom-bandH, bandH)
+	case domain.HomeDimmed:
+		blurImage(img, int(dimmedBlurPt*pt))
+		dimImage(img, dimmedStrength)
+	default:
+		bandH := max(int(homeBandHeightPt*pt), 1)
+		y := geo.DockTop + (geo.DockBottom-geo.DockTop)/2 - bandH/2
+		drawProgressBand(img, now, device, theme, y, bandH)
+	}
+}
+
+func drawProgressBand(img *image.RGBA, now time.Time, device domain.DeviceProfile, theme domain.Theme, y, h int) {
+	margin := int(homeBandMarginPt * device.PointScale())
+	x0 := margin
+	x1 := device.Width - margin
+
+	day, _, _ := domain.Progress(now)
+	filled := x0 + (x1-x0)*day/domain.DaysInYear(now.Year())
+
+	track := color.NRGBA{theme.Future.R, theme.Future.G, theme.Future.B, 90}
+	fill := color.NRGBA{theme.Active.R, theme.Active.G, theme.Active.B, 150}
+	mark := color.NRGBA{theme.Today.R, theme.Today.G, theme.Today.B, 220}
+
+	drawPill(img, image.Rect(x0, y, x1, y+h), track)
+	drawPill(img, image.Rect(x0, y, filled, y+h), fill)
+	blendCircle(img, filled, y+h/2, h, mark)
+}
+
+func drawHomePreviewOverlay(img *image.RGBA, device domain.DeviceProfile) {
+	geo := device.HomeGeometry()
+	pt := device.PointScale()
+	w := device.Width
+
+	blendRect(img, image.Rect(0, 0, w, geo.StatusBarBottom), previewZoneFill)
+	blendRect(img, image.Rect(0, geo.DockTop, w, geo.DockBottom), previewZoneFill)
+	blendRect(img, image.Rect(0, geo.DockTop, w, geo.DockTop+2), previewZoneEdge)
+
+	icon := int(iconSizePt * pt)
+	cellW := w / domain.IconGridColumns
+	cellH := (geo.IconGridBottom - geo.IconGridTop) / domain.IconGridRows
+
+	for r := 0; r < domain.IconGridRows; r++ {
+		for c := 0; c < domain.IconGridColumns; c++ {
+			cx := c*cellW + cellW/2
+			cy := geo.IconGridTop + r*cellH + cellH/2
+			drawRoundedRect(img, image.Rect(cx-icon/2, cy-icon/2, cx+icon/2, cy+icon/2), icon/4, previewControl)
+		}
+	}
+
+	dockY := geo.DockTop + (geo.DockBottom-geo.DockTop)/2
+	for c := 0; c < domain.IconGridColumns; c++ {
+		cx := c*cellW + cellW/2
+		drawRoundedRect(img, image.Rect(cx-icon/2, dockY-icon/2, cx+icon/2, dockY+icon/2), icon/4, previewControl)
+	}
+}
+
+func blurImage(img *image.RGBA, radius int) {
+	if radius < 1 {
+		return
+	}
+	for i := 0; i < 3; i++ {
+		boxBlurH(img, radius)
+		boxBlurV(img, radius)
+	}
+}
+
+func boxBlurH(img *image.RGBA, r int) {
+	b := img.Bounds()
+	w := b.Dx()
+	row := make([]color.RGBA, w)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := 0; x < w; x++ {
+			row[x] = img.RGBAAt(b.Min.X+x, y)
+		}
+		var sr, sg, sb int
+		for x := -r; x <= r; x++ {
+			c := row[clamp(x, 0, w-1)]
+			sr, sg, sb = sr+int(c.R), sg+int(c.G), sb+int(c.B)
+		}
+		n := 2*r + 1
+		for x := 0; x < w; x++ {
+			img.SetRGBA(b.Min.X+x, y, color.RGBA{uint8(sr / n), uint8(sg / n), uint8(sb / n), 255})
+			out := row[clamp(x-r, 0, w-1)]
+			in := row[clamp(x+r+1, 0, w-1)]
+			sr += int(in.R) - int(out.R)
+			sg += int(in.G) - int(out.G)
+			sb += int(in.B) - int(out.B)
+		}
+	}
+}
+
+func boxBlurV(img *image.RGBA, r int) {
+	b := img.Bounds()
+	h := b.Dy()
+	col := make([]color.RGBA, h)
+
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := 0; y < h; y++ {
+			col[y] = img.RGBAAt(x, b.Min.Y+y)
+		}
+		var sr, sg, sb int
+		for y := -r; y <= r; y++ {
+			c := col[clamp(y, 0, h-1)]
+			sr, sg, sb = sr+int(c.R), sg+int(c.G), sb+int(c.B)
+		}
+		n := 2*r + 1
+		for y := 0; y < h; y++ {
+			img.SetRGBA(x, b.Min.Y+y, color.RGBA{uint8(sr / n), uint8(sg / n), uint8(sb / n), 255})
+			out := col[clamp(y-r, 0, h-1)]
+			in := col[clamp(y+r+1, 0, h-1)]
+			sr += int(in.R) - int(out.R)
+			sg += int(in.G) - int(out.G)
+			sb += int(in.B) - int(out.B)
+		}
+	}
+}
+
+func dimImage(img *image.RGBA, k float64) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		img.Pix[i] = uint8(float64(img.Pix[i]) * k)
+		img.Pix[i+1] = uint8(float64(img.Pix[i+1]) * k)
+		img.Pix[i+2] = uint8(float64(img.Pix[i+2]) * k)
+	}
+}
@@ -0,0 +1,150 @@
+package rendering
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
+)
+
+// Thumbnails of the neighbouring months, relative to the lock-screen scale.
+const monthThumbScale = 0.6
+
+// renderMonth draws the current month large between the clock and the
+// lock-screen buttons, with the previous and next months as thumbnails
+// below it.
+func renderMonth(
+	ctx context.Context,
+	fonts *FontRegistry,
+	img *image.RGBA,
+	now time.Time,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	opts domain.RenderOptions,
+	scale float64,
+) {
+	msgs := domain.MessagesFor(opts.Lang)
+
+	top := device.GridTop(opts.Widgets)
+	bottom := device.ButtonsTop()
+
+	thumbScale := scale * monthThumbScale
+	thumbSpacing := dayGridSpacing(domain.DayDots, thumbScale)
+	thumbHeight := int(MonthBlockHeight * thumbScale)
+	gap := int(28 * scale)
+
+	areaHeight := bottom - thumbHeight - gap - top
+
+	// The month fills the area; the size setting can only shrink it.
+	bigScale := min(
+		float64(areaHeight)/(MonthBlockHeight+BaseSpacing),
+		float64(device.Width)*0.85/MonthBlockWidth,
+	) * min(opts.UIScale, 1)
+	faces := fonts.FontSet(bigScale, opts.Fonts)
+
+	m := domain.BuildMonth(now, now.Year(), now.Month(), opts.Lang, opts.WeekStart)
+	m.Name = msgs.MonthsLong[now.Month()-1]
+
+	// Centre the block, from the title's ascent to the last row, on the
+	// area. drawMonth moves the grid half a row down for the weekday
+	// header, so start half a row higher.
+	spacing := dayGridSpacing(domain.DayNumbers, bigScale)
+	above := int(52*bigScale) + spacing + faces.Month.Metrics().Ascent.Round()
+	below := 5*spacing + faces.Number.Metrics().Descent.Round()
+	gridY := top + (areaHeight-above-below)/2 + above
+
+	endGrid := tracing.StartSpan(ctx, "grid")
+	origin := drawMonth(
+		img,
+		device.Width/2,
+		gridY-spacing/2,
+		m,
+		theme,
+		opts.Weekends,
+		domain.DayNumbers,
+		msgs.RTL,
+		opts.WeekNumbers,
+		true,
+		device.Width,
+		bigScale,
+		faces,
+	)
+	drawDayMarks(img, origin, m, theme, opts.Events, msgs.RTL, bigScale, faces)
+	endGrid()
+
+	endThumbs := tracing.StartSpan(ctx, "thumbnails")
+	thumbFaces := fonts.FontSet(thumbScale, opts.Fonts)
+	thumbY := bottom - gap - 5*thumbSpacing
+	prev, next := device.Width/4, device.Width*3/4
+	if msgs.RTL {
+		prev, next = next, prev
+	}
+	for _, t := range []struct {
+		x     int
+		month time.Time
+	}{
+		{prev, now.AddDate(0, 0, -now.Day())},
+		{next, now.AddDate(0, 0, -now.Day()+1).AddDate(0, 1, 0)},
+	} {
+		thumb := domain.BuildMonth(now, t.month.Year(), t.month.Month(), opts.Lang, opts.WeekStart)
+		drawMonth(
+			img,
+			t.x,
+			thumbY,
+			thumb,
+			theme,
+			opts.Weekends,
+			domain.DayDots,
+			msgs.RTL,
+			false,
+			false,
+			device.Width/2,
+			thumbScale,
+			thumbFaces,
+		)
+	}
+	endThumbs()
+}
+
+// drawDayMarks rings today and puts a dot under each day with an event.
+func drawDayMarks(
+	img *image.RGBA,
+	origin image.Point,
+	m domain.MonthData,
+	theme domain.Theme,
+	events []domain.Event,
+	rtl bool,
+	scale float64,
+	faces FontSet,
+) {
+	spacing := dayGridSpacing(domain.DayNumbers, scale)
+	// Day numbers sit on the row's baseline; marks are placed around the
+	// middle of the digits.
+	capHeight := faces.Number.Metrics().CapHeight.Round()
+
+	for day := 0; day < m.Days; day++ {
+		p := dayCenter(origin, m, day, domain.DayNumbers, rtl, scale)
+		p.Y -= capHeight / 2
+
+		if m.IsCurrent && day == m.PassedDays-1 {
+			drawRing(img, p.X, p.Y, spacing*9/20, max(int(2*scale), 1), theme.Today)
+		}
+		if m.HasEvent(day, events) {
+			drawCircle(img, p.X, p.Y+capHeight/2+spacing/5, max(spacing/14, 1), theme.WeekendRed)
+		}
+	}
+}
+
+func drawRing(img *image.RGBA, cx, cy, r, width int, col color.Color) {
+	inner := r - width
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if d := x*x + y*y; d <= r*r && d > inner*inner {
+				img.Set(cx+x, cy+y, col)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package rendering
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+// Device geometry below is in iOS points, converted with
+// DeviceProfile.PointScale.
+const (
+	islandWidthPt  = 126
+	islandHeightPt = 37
+	islandTopPt    = 11
+
+	homeIndicatorWidthPt  = 134
+	homeIndicatorHeightPt = 5
+
+	punchHoleRadiusDp = 6
+
+	lockButtonRadiusPt = 25
+	lockButtonInsetPt  = 50
+
+	dockIconPt    = 48
+	dockPaddingPt = 8
+	dockIcons     = 12
+)
+
+var (
+	previewZoneFill   = color.NRGBA{120, 180, 255, 40}
+	previewZoneEdge   = color.NRGBA{120, 180, 255, 160}
+	previewInsetFill  = color.NRGBA{255, 120, 120, 50}
+	previewWidgetFill = color.NRGBA{160, 255, 160, 40}
+	previewControl    = color.NRGBA{255, 255, 255, 60}
+	previewMockText   = color.NRGBA{255, 255, 255, 230}
+	previewIslandFill = color.RGBA{0, 0, 0, 255}
+)
+
+func drawPreviewOverlay(img *image.RGBA, now time.Time, device domain.DeviceProfile, opts domain.RenderOptions, faces FontSet) {
+	if device.Platform == domain.PlatformMacOS {
+		drawDesktopPreviewOverlay(img, device, opts)
+		return
+	}
+
+	w := device.Width
+	h := device.Height
+	pt := device.PointScale()
+
+	clockBottom := device.ClockBottom()
+	buttonsTop := device.ButtonsTop()
+	inset := device.BottomInsetPx()
+
+	blendRect(img, image.Rect(0, 0, w, clockBottom), previewZoneFill)
+	blendRect(img, image.Rect(0, clockBottom-2, w, clockBottom), previewZoneEdge)
+
+	if band := device.WidgetBand(opts.Widgets); band > 0 {
+		blendRect(img, image.Rect(0, clockBottom, w, clockBottom+band), previewWidgetFill)
+	}
+
+	blendRect(img, image.Rect(0, buttonsTop, w, h), previewZoneFill)
+	blendRect(img, image.Rect(0, buttonsTop, w, buttonsTop+2), previewZoneEdge)
+
+	if inset > 0 {
+		blendRect(img, image.Rect(0, h-inset, w, h), previewInsetFill)
+	}
+
+	if device.Platform == domain.PlatformAndroid {
+		drawAndroidMockClock(img, now, device, opts.Lang, faces)
+	} else {
+		dateY := int(float64(clockBottom) * 0.32)
+		clockY := int(float64(clockBottom) * 0.78)
+		if device.IsLandscape() {
+			// The clock zone is too short for the portrait proportions, so
+			// hang the date right above the clock glyphs instead.
+			dateY = clockY - faces.Clock.Metrics().CapHeight.Round() - faces.Date.Metrics().Descent.Round()*3
+		}
+		drawText(img, domain.LockScreenDate(now, opts.Lang), w/2, dateY, previewMockText, faces.Date)
+		drawText(img, now.Format("15:04"), w/2, clockY, previewMockText, faces.Clock)
+	}
+
+	radius := int(lockButtonRadiusPt * pt)
+	buttonInset := int(lockButtonInsetPt * pt)
+	buttonY := max(h-inset-buttonInset-radius, buttonsTop+radius)
+	blendCircle(img, buttonInset+radius, buttonY, radius, previewControl)
+	blendCircle(img, w-buttonInset-radius, buttonY, radius, previewControl)
+
+	if device.BottomInset > 0 {
+		barW := int(homeIndicatorWidthPt * pt)
+		barH := max(int(homeIndicatorHeightPt*pt), 1)
+		drawPill(img, image.Rect(w/2-barW/2, h-inset/2-barH/2, w/2+barW/2, h-inset/2+barH/2+barH%2), previewMockText)
+	}
+
+	if device.DynamicIsland {
+		islandW := int(islandWidthPt * pt)
+		islandH := int(islandHeightPt * pt)
+		top := int(islandTopPt * pt)
+		drawPill(img, image.Rect(w/2-islandW/2, top, w/2+islandW/2, top+islandH), previewIslandFill)
+	}
+
+	if device.PunchHole {
+		r := int(punchHoleRadiusDp * pt)
+		drawCircle(img, w/2, device.HomeGeometry().StatusBarBottom/2, r, previewIslandFill)
+	}
+}
+
+// Desktops have no lock-screen clock; the reserved zones are the menu bar
+// at the top and the Dock at the bottom.
+func drawDesktopPreviewOverlay(img *image.RGBA, device domain.DeviceProfile, opts domain.RenderOptions) {
+	w := device.Width
+	h := device.Height
+	pt := device.PointScale()
+
+	menuBottom := device.ClockBottom()
+	dockTop := device.ButtonsTop()
+
+	blendRect(img, image.Rect(0, 0, w, menuBottom), previewZoneFill)
+	blendRect(img, image.Rect(0, menuBottom-2, w, menuBottom), previewZoneEdge)
+
+	if band := device.WidgetBand(opts.Widgets); band > 0 {
+		blendRect(img, image.Rect(0, menuBottom, w, menuBottom+band), previewWidgetFill)
+	}
+
+	blendRect(img, image.Rect(0, dockTop, w, dockTop+2), previewZoneEdge)
+
+	icon := int(dockIconPt * pt)
+	pad := int(dockPaddingPt * pt)
+	dockW := dockIcons*(icon+pad) + pad
+	dockH := icon + 2*pad
+	dockY := dockTop + (h-dockTop-dockH)/2
+	drawRoundedRect(img, image.Rect(w/2-dockW/2, dockY, w/2+dockW/2, dockY+dockH), pad*2, previewZoneFill)
+
+	x := w/2 - dockW/2 + pad
+	for i := 0; i < dockIcons; i++ {
+		drawRoundedRect(img, image.Rect(x, dockY+pad, x+icon, dockY+pad+icon), icon/4, previewControl)
+		x += icon + pad
+	}
+}
+
+// Android lock screens stack hours over minutes under a date line that
+// sits just below the status bar.
+func drawAndroidMockClock(img *image.RGBA, now time.Time, device domain.DeviceProfile, lang string, faces FontSet) {
+	w := device.Width
+	clockBottom := device.ClockBottom()
+	statusBottom := device.HomeGeometry().StatusBarBottom
+	lineH := faces.Clock.Metrics().Height.Round()
+
+	drawText(img, domain.LockScreenDate(now, lang), w/2, statusBottom+faces.Date.Metrics().Height.Round()*2, previewMockText, faces.Date)
+	drawText(img, now.Format("15"), w/2, clockBottom-lineH*9/10, previewMockText, faces.Clock)
+	drawText(img, now.Format("04"), w/2, clockBottom-lineH/10, previewMockText, faces.Clock)
+}
+
+func blendRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func blendCircle(img *image.RGBA, cx, cy, r int, c color.Color) {
+	src := image.NewUniform(c)
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				draw.Draw(img, image.Rect(cx+x, cy+y, cx+x+1, cy+y+1), src, image.Point{}, draw.Over)
+			}
+		}
+	}
+}
+
+func drawPill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	drawRoundedRect(img, r, r.Dy()/2, c)
+}
+
+func drawRoundedRect(img *image.RGBA, r image.Rectangle, radius int, c color.Color) {
+	src := image.NewUniform(c)
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cx := clamp(x, r.Min.X+radius, r.Max.X-radius-1)
+			cy := clamp(y, r.Min.Y+radius, r.Max.Y-radius-1)
+			dx, dy := x-cx, y-cy
+			if dx*dx+dy*dy <= radius*radius {
+				draw.Draw(img, image.Rect(x, y, x+1, y+1), src, image.Point{}, draw.Over)
+			}
+		}
+	}
+}
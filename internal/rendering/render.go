@@ -1,14 +1,17 @@
 package rendering
 
 import (
-	"container/list"
+	"context"
 	"fmt"
 	"image"
 	"image/color"
-	"sync"
+	"image/draw"
+	"strconv"
 	"time"
 
 	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
+
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
 )
@@ -20,9 +23,15 @@ const (
 	BaseMonthFont  = 38
 	BaseFooterFont = 30
 	BaseNumberFont = 22
+	BaseClockFont  = 250
+	BaseDateFont   = 56
 
 	BaseDotRadius = 6
 	BaseSpacing   = 32
+
+	// Size of one month cell at scale 1: title, six grid rows and a gap.
+	MonthBlockHeight = 280
+	MonthBlockWidth  = 260
 )
 
 const (
@@ -35,136 +44,197 @@ type FontSet struct {
 	Month  font.Face
 	Footer font.Face
 	Number font.Face
-}
-
-const maxFontCacheEntries = 64
-
-type fontCacheEntry struct {
-	key   string
-	faces FontSet
-}
-
-var fontCache struct {
-	sync.Mutex
-	items map[string]*list.Element
-	order *list.List
+	Clock  font.Face
+	Date   font.Face
 }
 
 func RenderCalendar(
+	ctx context.Context,
+	fonts *FontRegistry,
 	now time.Time,
 	device domain.DeviceProfile,
 	theme domain.Theme,
-	mode string,
-	lang string,
-	weekends string,
-	dayStyle domain.DayStyle,
-	uiScale float64,
-	bgStyle domain.BackgroundStyle,
-	bgColor string,
+	opts domain.RenderOptions,
 ) *image.RGBA {
 
-	deviceScale := float64(device.Width) / float64(BaseWidth)
-	scale := deviceScale * uiScale
+	// Scale by the short side so dots keep their size on landscape screens.
+	deviceScale := min(float64(device.Width), float64(device.Height)) / float64(BaseWidth)
+	scale := deviceScale * opts.UIScale
 
-	faces := getFontSet(scale)
+	faces := fonts.FontSet(scale, opts.Fonts)
 
 	img := image.NewRGBA(image.Rect(0, 0, device.Width, device.Height))
-	drawBackground(img, device, bgStyle, bgColor)
+	endBackground := tracing.StartSpan(ctx, "background")
+	drawBackground(img, device, opts.BgStyle, opts.BgColor)
+	endBackground()
+
+	home := opts.Screen == domain.ScreenHome
+	if !home || opts.HomeStyle == domain.HomeDimmed {
+		switch opts.Mode {
+		case domain.ModeMonths:
+			renderMonths(ctx, fonts, img, now, device, theme, opts, scale, faces)
+		case domain.ModeMonth:
+			renderMonth(ctx, fonts, img, now, device, theme, opts, scale)
+		case domain.ModeWeek:
+			renderWeek(ctx, fonts, img, now, device, theme, opts, scale, faces)
+		case domain.ModeHeatmap:
+			renderHeatmap(ctx, fonts, img, now, device, theme, opts, scale, faces)
+		}
+	}
 
-	if mode != "months" {
-		return img
+	if home {
+		endHome := tracing.StartSpan(ctx, "home")
+		drawHomeScreen(img, now, device, theme, opts.HomeStyle)
+		endHome()
 	}
 
-	months := domain.BuildMonths(now, lang)
+	if opts.Preview {
+		endPreview := tracing.StartSpan(ctx, "preview")
+		if home {
+			drawHomePreviewOverlay(img, device)
+		} else {
+			drawPreviewOverlay(img, now, device, opts, fonts.FontSet(deviceScale, opts.Fonts))
+		}
+		endPreview()
+	}
 
-	safeTop := device.ClockBottom()
-	safeBottom := device.ButtonsTop()
+	return img
+}
 
-	footerHeight := int(52 * scale)
-	footerGap := int(28 * scale)
+func renderMonths(
+	ctx context.Context,
+	fonts *FontRegistry,
+	img *image.RGBA,
+	now time.Time,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	opts domain.RenderOptions,
+	scale float64,
+	faces FontSet,
+) {
+	months := domain.BuildMonths(now, opts.Lang, opts.WeekStart)
+	rtl := domain.MessagesFor(opts.Lang).RTL
 
-	gridTop := safeTop
-	gridBottom := safeBottom - footerHeight - footerGap
+	gridTop, gridBottom, footerY := footerLayout(device, opts, scale)
 	gridHeight := gridBottom - gridTop
 
+	cols, rows := chooseGrid(device.Width, gridHeight)
+
+	gridScale, gridFaces := scale, faces
+	blockHeight := float64(MonthBlockHeight)
+	if opts.WeekdayHeader {
+		blockHeight += BaseSpacing
+	}
+	if opts.Widgets != domain.WidgetsNone || !device.IsPhone() || opts.WeekdayHeader {
+		fit := min(
+			float64(gridHeight/rows)/(blockHeight*scale),
+			float64(device.Width/cols)/(MonthBlockWidth*scale),
+		)
+		if fit < 1 {
+			gridScale = scale * fit
+			gridFaces = fonts.FontSet(gridScale, opts.Fonts)
+		}
+	}
+
+	endGrid := tracing.StartSpan(ctx, "grid")
 	drawMonths(
 		img,
 		months,
 		device,
+		cols,
+		rows,
 		gridHeight,
 		gridTop,
 		theme,
-		weekends,
-		dayStyle,
-		scale,
-		faces,
+		opts.Weekends,
+		opts.DayStyle,
+		rtl,
+		opts.WeekNumbers,
+		opts.WeekdayHeader,
+		gridScale,
+		gridFaces,
 	)
+	endGrid()
 
-	footerY := device.ButtonsTop() + int(80*scale)
-	drawFooterAtY(img, now, device, theme, lang, footerY, faces)
+	if opts.Footer.Position == domain.FooterHidden {
+		return
+	}
 
-	return img
+	endFooter := tracing.StartSpan(ctx, "footer")
+	drawFooter(img, now, device, theme, opts, footerY, scale, faces)
+	endFooter()
 }
 
-func getFontSet(scale float64) FontSet {
-	key := fmt.Sprintf("%.4f", scale)
-
-	fontCache.Lock()
-	defer fontCache.Unlock()
-
-	if fontCache.items == nil {
-		fontCache.items = make(map[string]*list.Element)
-		fontCache.order = list.New()
+// drawFooter draws the year's progress as text or a bar with baseline y.
+func drawFooter(
+	img *image.RGBA,
+	now time.Time,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	opts domain.RenderOptions,
+	y int,
+	scale float64,
+	faces FontSet,
+) {
+	if opts.Footer.Style == domain.FooterBar {
+		day, _, _ := domain.Progress(now)
+		drawProgressBar(img, float64(day)/float64(domain.DaysInYear(now.Year())), device, theme, y, scale)
+		return
 	}
+	drawText(img, opts.Footer.Text(now, opts.Lang), device.Width/2, y, theme.Text, faces.Footer)
+}
 
-	if el, ok := fontCache.items[key]; ok {
-		fontCache.order.MoveToFront(el)
-		return el.Value.(fontCacheEntry).faces
-	}
+// footerLayout splits the space between the clock and the lock-screen
+// buttons into the calendar's area and the footer's baseline.
+func footerLayout(device domain.DeviceProfile, opts domain.RenderOptions, scale float64) (top, bottom, footerY int) {
+	safeTop := device.GridTop(opts.Widgets)
+	safeBottom := device.ButtonsTop()
 
-	fontBytes := mustRead("fonts/SFPRODISPLAYBOLD.OTF")
-	f := mustParseFont(fontBytes)
+	footerHeight := int(52 * scale)
+	footerGap := int(28 * scale)
 
-	faces := FontSet{
-		Month:  mustFace(f, BaseMonthFont*scale*MonthTitleScale),
-		Footer: mustFace(f, BaseFooterFont*scale*FooterScale),
-		Number: mustFace(f, BaseNumberFont*scale*DayGridScale),
+	// Phones put the footer between the lock-screen buttons; tablets and
+	// desktops keep it above the dock.
+	footerY = device.ButtonsTop() + int(80*scale)
+	if !device.IsPhone() {
+		footerY = safeBottom - footerGap
 	}
-	el := fontCache.order.PushFront(fontCacheEntry{key: key, faces: faces})
-	fontCache.items[key] = el
 
-	if fontCache.order.Len() > maxFontCacheEntries {
-		oldest := fontCache.order.Back()
-		if oldest != nil {
-			fontCache.order.Remove(oldest)
-			oldKey := oldest.Value.(fontCacheEntry).key
-			delete(fontCache.items, oldKey)
-		}
+	switch opts.Footer.Position {
+	case domain.FooterAbove:
+		return safeTop + footerHeight + footerGap, safeBottom, safeTop + footerHeight
+	case domain.FooterHidden:
+		return safeTop, safeBottom, footerY
+	default:
+		return safeTop, safeBottom - footerHeight - footerGap, footerY
 	}
-	return faces
 }
 
 func drawMonths(
 	img *image.RGBA,
 	months []domain.MonthData,
 	device domain.DeviceProfile,
+	cols, rows int,
 	usableHeight int,
 	offsetY int,
 	theme domain.Theme,
 	weekends string,
 	dayStyle domain.DayStyle,
+	rtl bool,
+	weekNums bool,
+	weekdays bool,
 	scale float64,
 	faces FontSet,
 ) {
-	const cols = 3
-	const rows = 4
-
 	cellW := device.Width / cols
 	cellH := usableHeight / rows
 
 	for i, m := range months {
 		c := i % cols
 		r := i / cols
+		if rtl {
+			c = cols - 1 - c
+		}
 
 		cx := c*cellW + cellW/2
 		cy := offsetY + r*cellH + cellH/2
@@ -177,6 +247,9 @@ func drawMonths(
 			theme,
 			weekends,
 			dayStyle,
+			rtl,
+			weekNums,
+			weekdays,
 			cellW,
 			scale,
 			faces,
@@ -184,6 +257,22 @@ func drawMonths(
 	}
 }
 
+// chooseGrid picks the month grid that best matches the aspect ratio of
+// the area available for it.
+func chooseGrid(width, height int) (cols, rows int) {
+	if height <= 0 {
+		return 3, 4
+	}
+	switch aspect := float64(width) / float64(height); {
+	case aspect < 1.2:
+		return 3, 4
+	case aspect < 2.0:
+		return 4, 3
+	default:
+		return 6, 2
+	}
+}
+
 func drawMonth(
 	img *image.RGBA,
 	cx, cy int,
@@ -191,11 +280,21 @@ func drawMonth(
 	theme domain.Theme,
 	weekends string,
 	style domain.DayStyle,
+	rtl bool,
+	weekNums bool,
+	weekdays bool,
 	cellW int,
 	scale float64,
 	faces FontSet,
-) {
+) image.Point {
 	titleOffset := int(52 * scale)
+	if weekdays {
+		// The header takes one grid row above the days; split it so the
+		// block stays centred in its cell.
+		spacing := dayGridSpacing(style, scale)
+		cy += spacing / 2
+		titleOffset += spacing
+	}
 
 	titleColor := theme.Text
 	if m.IsCurrent {
@@ -204,14 +303,41 @@ func drawMonth(
 
 	drawText(img, m.Name, cx, cy-titleOffset, titleColor, faces.Month)
 
+	if weekNums {
+		// Keep the days plus the week column centred under the title.
+		shift := dayGridSpacing(style, scale) / 2
+		if rtl {
+			shift = -shift
+		}
+		cx += shift
+		drawWeekNumbers(img, cx, cy, m, theme, style, rtl, scale, faces)
+	}
+	if weekdays {
+		drawWeekdayHeader(img, cx, cy, m, theme, weekends, style, rtl, scale, faces)
+	}
+
 	switch style {
 	case domain.DayDots:
-		drawMonthDots(img, cx, cy, m, theme, weekends, scale)
+		drawMonthDots(img, cx, cy, m, theme, weekends, rtl, scale)
 	case domain.DayBars:
-		drawMonthBars(img, cx, cy, m, theme, weekends, scale)
+		drawMonthBars(img, cx, cy, m, theme, weekends, rtl, scale)
 	case domain.DayNumbers:
-		drawMonthNumbers(img, cx, cy, m, theme, weekends, scale, faces)
+		drawMonthNumbers(img, cx, cy, m, theme, weekends, rtl, scale, faces)
 	}
+	return image.Pt(cx, cy)
+}
+
+// dayCenter is where day sits in a grid that drawMonth laid out around
+// origin.
+func dayCenter(origin image.Point, m domain.MonthData, day int, style domain.DayStyle, rtl bool, scale float64) image.Point {
+	const cols = 7
+	spacing := dayGridSpacing(style, scale)
+	col := (m.StartWeekday + day) % cols
+	row := (m.StartWeekday + day) / cols
+	return image.Pt(
+		origin.X-(cols-1)*spacing/2+dayColumn(col, cols, rtl)*spacing,
+		origin.Y+row*spacing,
+	)
 }
 
 func drawMonthDots(
@@ -220,12 +346,13 @@ func drawMonthDots(
 	m domain.MonthData,
 	theme domain.Theme,
 	weekends string,
+	rtl bool,
 	scale float64,
 ) {
 	gridScale := scale * DayGridScale
 
 	cols := 7
-	spacing := int(BaseSpacing * gridScale)
+	spacing := dayGridSpacing(domain.DayDots, scale)
 	radius := int(BaseDotRadius * gridScale)
 
 	startX := cx - (cols-1)*spacing/2
@@ -235,10 +362,10 @@ func drawMonthDots(
 		col := (m.StartWeekday + day) % 7
 		row := (m.StartWeekday + day) / 7
 
-		x := startX + col*spacing
+		x := startX + dayColumn(col, cols, rtl)*spacing
 		y := startY + row*spacing
 
-		drawCircle(img, x, y, radius, resolveDayColor(day, col, m, theme, weekends))
+		drawCircle(img, x, y, radius, resolveDayColor(day, m, theme, weekends))
 	}
 }
 
@@ -248,12 +375,13 @@ func drawMonthBars(
 	m domain.MonthData,
 	theme domain.Theme,
 	weekends string,
+	rtl bool,
 	scale float64,
 ) {
 	gridScale := scale * DayGridScale
 
 	cols := 7
-	spacing := int(BaseSpacing * gridScale)
+	spacing := dayGridSpacing(domain.DayBars, scale)
 
 	barW := int(20 * gridScale)
 	barH := int(6 * gridScale)
@@ -265,11 +393,11 @@ func drawMonthBars(
 		col := (m.StartWeekday + day) % 7
 		row := (m.StartWeekday + day) / 7
 
-		x := startX + col*spacing
+		x := startX + dayColumn(col, cols, rtl)*spacing
 		y := startY + row*spacing
 
 		drawRect(img, x-barW/2, y-barH/2, barW, barH,
-			resolveDayColor(day, col, m, theme, weekends))
+			resolveDayColor(day, m, theme, weekends))
 	}
 }
 
@@ -279,13 +407,12 @@ func drawMonthNumbers(
 	m domain.MonthData,
 	theme domain.Theme,
 	weekends string,
+	rtl bool,
 	scale float64,
 	faces FontSet,
 ) {
-	gridScale := scale * DayGridScale
-
 	cols := 7
-	spacing := int(30 * gridScale)
+	spacing := dayGridSpacing(domain.DayNumbers, scale)
 
 	startX := cx - (cols-1)*spacing/2
 	startY := cy
@@ -294,7 +421,7 @@ func drawMonthNumbers(
 		col := (m.StartWeekday + day) % 7
 		row := (m.StartWeekday + day) / 7
 
-		x := startX + col*spacing
+		x := startX + dayColumn(col, cols, rtl)*spacing
 		y := startY + row*spacing
 
 		drawText(
@@ -302,41 +429,125 @@ func drawMonthNumbers(
 			fmt.Sprintf("%d", day+1),
 			x,
 			y,
-			resolveDayColor(day, col, m, theme, weekends),
+			resolveDayColor(day, m, theme, weekends),
 			faces.Number,
 		)
 	}
 }
 
-func drawFooterAtY(
+func dayGridSpacing(style domain.DayStyle, scale float64) int {
+	if style == domain.DayNumbers {
+		return int(30 * scale * DayGridScale)
+	}
+	return int(BaseSpacing * scale * DayGridScale)
+}
+
+// drawWeekNumbers puts the ISO week of each row in an extra column before
+// the first weekday and highlights the row holding today.
+func drawWeekNumbers(
 	img *image.RGBA,
-	now time.Time,
-	device domain.DeviceProfile,
+	cx, cy int,
+	m domain.MonthData,
 	theme domain.Theme,
-	lang string,
-	y int,
+	style domain.DayStyle,
+	rtl bool,
+	scale float64,
 	faces FontSet,
 ) {
-	day := now.YearDay()
-	total := domain.DaysInYear(now.Year())
-	left := total - day
-	percent := int(float64(day) / float64(total) * 100)
+	const cols = 7
+	spacing := dayGridSpacing(style, scale)
+	startX := cx - (cols-1)*spacing/2
+	x := startX + dayColumn(-1, cols, rtl)*spacing
+
+	baseline := labelBaseline(style, faces.Number)
+	center := baseline - faces.Number.Metrics().CapHeight.Round()/2
+
+	today := m.TodayRow()
+	for row, week := range m.WeekNumbers {
+		y := cy + row*spacing
+		col := theme.Future
+		if row == today {
+			col = theme.Today
+			left, right := x-spacing/2, startX+(cols-1)*spacing+spacing/2
+			if rtl {
+				left, right = startX-spacing/2, x+spacing/2
+			}
+			band := image.Rect(left, y+center-spacing*2/5, right, y+center+spacing*2/5)
+			highlight := theme.Today
+			highlight.A = 48
+			draw.Draw(img, band, image.NewUniform(color.NRGBA(highlight)), image.Point{}, draw.Over)
+		}
+		drawText(img, strconv.Itoa(week), x, y+baseline, col, faces.Number)
+	}
+}
 
-	drawText(
-		img,
-		footerText(left, percent, lang),
-		device.Width/2,
-		y,
-		theme.Text,
-		faces.Footer,
-	)
+// drawWeekdayHeader labels the day columns with weekday initials one row
+// above the first week.
+func drawWeekdayHeader(
+	img *image.RGBA,
+	cx, cy int,
+	m domain.MonthData,
+	theme domain.Theme,
+	weekends string,
+	style domain.DayStyle,
+	rtl bool,
+	scale float64,
+	faces FontSet,
+) {
+	const cols = 7
+	spacing := dayGridSpacing(style, scale)
+	startX := cx - (cols-1)*spacing/2
+	y := cy - spacing + labelBaseline(style, faces.Number)
+
+	for col, initial := range m.WeekdayInitials {
+		var c color.Color = theme.Future
+		if wc, ok := weekendColor(m.Weekday(col-m.StartWeekday), theme, weekends); ok {
+			c = wc
+		}
+		drawText(img, initial, startX+dayColumn(col, cols, rtl)*spacing, y, c, faces.Number)
+	}
+}
+
+// labelBaseline offsets text from a grid row's y: dots and bars are
+// centred on the row, numbers sit on its baseline.
+func labelBaseline(style domain.DayStyle, face font.Face) int {
+	if style == domain.DayNumbers {
+		return 0
+	}
+	return face.Metrics().CapHeight.Round() / 2
 }
 
-func footerText(left, percent int, lang string) string {
-	if lang == "ru" {
-		return fmt.Sprintf("%d дн. осталось   %d%%", left, percent)
+// dayColumn mirrors the weekday column for right-to-left languages.
+func dayColumn(col, cols int, rtl bool) int {
+	if rtl {
+		return cols - 1 - col
+	}
+	return col
+}
+
+// drawProgressBar draws done, a share from 0 to 1, as a rounded bar
+// sitting where a line of footer text with baseline y would be.
+func drawProgressBar(
+	img *image.RGBA,
+	done float64,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	y int,
+	scale float64,
+) {
+	h := max(int(12*scale), 2)
+	w := int(min(600*scale, float64(device.Width)*0.8))
+	r := h / 2
+	x0 := (device.Width - w) / 2
+	cy := y - int(10*scale)
+
+	split := x0 + int(float64(w)*done)
+	drawRect(img, x0+r, cy-r, w-2*r, h, theme.Future)
+	drawCircle(img, x0+w-r, cy, r, theme.Future)
+	drawCircle(img, x0+r, cy, r, theme.Active)
+	if split > x0+r {
+		drawRect(img, x0+r, cy-r, split-x0-r, h, theme.Active)
 	}
-	return fmt.Sprintf("%d d left   %d%%", left, percent)
 }
 
 func drawText(img *image.RGBA, text string, cx, y int, col color.Color, face font.Face) {
@@ -345,6 +556,7 @@ func drawText(img *image.RGBA, text string, cx, y int, col color.Color, face fon
 		Src:  image.NewUniform(col),
 		Face: face,
 	}
+	text = visualText(text)
 	w := d.MeasureString(text).Round()
 	d.Dot = fixed.P(cx-w/2, y)
 	d.DrawString(text)
@@ -368,24 +580,32 @@ func drawRect(img *image.RGBA, x, y, w, h int, col color.Color) {
 	}
 }
 
-func resolveDayColor(day, col int, m domain.MonthData, theme domain.Theme, weekends string) color.Color {
+func resolveDayColor(day int, m domain.MonthData, theme domain.Theme, weekends string) color.Color {
 	if m.IsCurrent && day == m.PassedDays-1 {
 		return theme.Today
 	}
 	if day < m.PassedDays {
 		return theme.Active
 	}
-	if weekends != "off" && (col == 5 || col == 6) {
-		switch weekends {
-		case "gray":
-			return theme.WeekendGray
-		case "green":
-			return theme.WeekendGreen
-		case "blue":
-			return theme.WeekendBlue
-		case "red":
-			return theme.WeekendRed
-		}
+	if c, ok := weekendColor(m.Weekday(day), theme, weekends); ok {
+		return c
 	}
 	return theme.Future
 }
+
+func weekendColor(wd time.Weekday, theme domain.Theme, weekends string) (color.Color, bool) {
+	if wd != time.Saturday && wd != time.Sunday {
+		return nil, false
+	}
+	switch weekends {
+	case "gray":
+		return theme.WeekendGray, true
+	case "green":
+		return theme.WeekendGreen, true
+	case "blue":
+		return theme.WeekendBlue, true
+	case "red":
+		return theme.WeekendRed, true
+	}
+	return nil, false
+}
@@ -0,0 +1,24 @@
+package rendering
+
+import "testing"
+
+func TestChooseGrid(t *testing.T) {
+	tests := []struct {
+		width, height int
+		cols, rows    int
+	}{
+		{1179, 1500, 3, 4},
+		{119, 100, 3, 4},
+		{120, 100, 4, 3},
+		{199, 100, 4, 3},
+		{200, 100, 6, 2},
+		{2560, 800, 6, 2},
+		{1000, 0, 3, 4},
+	}
+	for _, tt := range tests {
+		cols, rows := chooseGrid(tt.width, tt.height)
+		if cols != tt.cols || rows != tt.rows {
+			t.Errorf("chooseGrid(%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, cols, rows, tt.cols, tt.rows)
+		}
+	}
+}
@@ -1,36 +1,78 @@
 package rendering
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"image"
+	"io/fs"
 	"time"
 
 	"calendar-wallpaper/internal/domain"
 )
 
-type Renderer struct{}
+type Renderer struct {
+	Fonts *FontRegistry
+}
+
+// NewRenderer parses the fonts in fsys; a missing or broken font is
+// reported here rather than on the first render.
+func NewRenderer(fsys fs.FS) (Renderer, error) {
+	fonts, err := LoadFonts(fsys)
+	if err != nil {
+		return Renderer{}, err
+	}
+	return Renderer{Fonts: fonts}, nil
+}
+
+var selfTestDevice = domain.DeviceProfile{
+	Key:              "self-test",
+	Width:            64,
+	Height:           128,
+	ClockZoneRatio:   0.30,
+	ButtonsZoneRatio: 0.80,
+}
 
-func (Renderer) RenderCalendar(
+func (r Renderer) RenderCalendar(
+	ctx context.Context,
 	now time.Time,
 	device domain.DeviceProfile,
 	theme domain.Theme,
-	mode string,
-	lang string,
-	weekends string,
-	dayStyle domain.DayStyle,
-	uiScale float64,
-	bgStyle domain.BackgroundStyle,
-	bgColor string,
+	opts domain.RenderOptions,
 ) *image.RGBA {
-	return RenderCalendar(
-		now,
-		device,
-		theme,
-		mode,
-		lang,
-		weekends,
-		dayStyle,
-		uiScale,
-		bgStyle,
-		bgColor,
+	return RenderCalendar(ctx, r.Fonts, now, device, theme, opts)
+}
+
+func (r Renderer) SelfTest() (err error) {
+	if r.Fonts == nil {
+		return errors.New("render self-test: fonts are not loaded")
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("render self-test: %v", rec)
+		}
+	}()
+
+	img := RenderCalendar(
+		context.Background(),
+		r.Fonts,
+		time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC),
+		selfTestDevice,
+		domain.IOSTheme(),
+		domain.RenderOptions{
+			Mode:     domain.ModeMonths,
+			Lang:     "en",
+			Weekends: "off",
+			DayStyle: domain.DayDots,
+			UIScale:  1,
+			BgStyle:  domain.BgPlain,
+			BgColor:  "black",
+			Preview:  true,
+		},
 	)
+	if img.Bounds().Dx() != selfTestDevice.Width || img.Bounds().Dy() != selfTestDevice.Height {
+		return fmt.Errorf("render self-test: unexpected bounds %v", img.Bounds())
+	}
+	return nil
 }
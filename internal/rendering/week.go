@@ -0,0 +1,124 @@
+package rendering
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"time"
+
+	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
+)
+
+// Week labels are drawn larger than the month grid's.
+const weekLabelScale = 1.5
+
+// renderWeek draws the days of the current week as columns filling from
+// the top as each day passes.
+func renderWeek(
+	ctx context.Context,
+	fonts *FontRegistry,
+	img *image.RGBA,
+	now time.Time,
+	device domain.DeviceProfile,
+	theme domain.Theme,
+	opts domain.RenderOptions,
+	scale float64,
+	faces FontSet,
+) {
+	msgs := domain.MessagesFor(opts.Lang)
+	days := domain.BuildWeek(now, opts.Lang, opts.WeekStart)
+	top, bottom, footerY := footerLayout(device, opts, scale)
+
+	labels := fonts.FontSet(scale*weekLabelScale, opts.Fonts)
+	nameAscent := labels.Number.Metrics().Ascent.Round()
+	dateAscent := labels.Month.Metrics().Ascent.Round()
+	gap := int(24 * scale)
+
+	colW := int(min(float64(device.Width)*0.85, 900*scale)) / 7
+	barW := colW * 11 / 20
+	startX := device.Width/2 - 3*colW
+
+	nameY := top + gap + nameAscent
+	barTop := nameY + gap
+	dateY := bottom - gap
+	barBottom := dateY - dateAscent - gap
+
+	endGrid := tracing.StartSpan(ctx, "grid")
+	for i, d := range days {
+		x := startX + dayColumn(i, 7, msgs.RTL)*colW
+
+		fill := theme.Active
+		label := color.Color(theme.Future)
+		if wc, ok := weekendColor(d.Weekday, theme, opts.Weekends); ok {
+			label = wc
+		}
+		if d.IsToday {
+			fill = theme.Today
+			label = theme.Today
+		}
+
+		drawCapsule(img, x, barTop, barBottom, barW, d.Passed, theme.Future, fill)
+		drawText(img, d.Name, x, nameY, label, labels.Number)
+		drawText(img, msgs.Number(d.Date.Day()), x, dateY, label, labels.Month)
+	}
+	endGrid()
+
+	if opts.Footer.Position == domain.FooterHidden {
+		return
+	}
+
+	endFooter := tracing.StartSpan(ctx, "footer")
+	done := domain.WeekProgress(days)
+	switch {
+	case opts.Footer.Style == domain.FooterBar:
+		drawProgressBar(img, done, device, theme, footerY, scale)
+	case opts.Footer.Template != "":
+		drawText(img, opts.Footer.Text(now, opts.Lang), device.Width/2, footerY, theme.Text, faces.Footer)
+	default:
+		drawText(img, msgs.PercentText(int(done*100)), device.Width/2, footerY, theme.Text, faces.Footer)
+	}
+	endFooter()
+}
+
+// drawCapsule draws a vertical bar with round ends between y0 and y1,
+// filled with fill from the top down to share done and with track below.
+func drawCapsule(img *image.RGBA, cx, y0, y1, w int, done float64, track, fill color.Color) {
+	r := w / 2
+	if y1-y0 < w {
+		return
+	}
+	split := y0 + int(float64(y1-y0)*done)
+
+	span := func(from, to int, col color.Color) {
+		for y := from; y < to; y++ {
+			// Distance into the rounded end, if y is inside one.
+			dy := 0
+			if y < y0+r {
+				dy = y0 + r - y
+			} else if y >= y1-r {
+				dy = y - (y1 - r - 1)
+			}
+			half := r
+			if dy > 0 {
+				half = isqrt(r*r - dy*dy)
+			}
+			for x := cx - half; x <= cx+half; x++ {
+				img.Set(x, y, col)
+			}
+		}
+	}
+	span(y0, split, fill)
+	span(split, y1, track)
+}
+
+func isqrt(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	x := n
+	for y := (x + 1) / 2; y < x; y = (x + n/x) / 2 {
+		x = y
+	}
+	return x
+}
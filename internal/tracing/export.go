@@ -0,0 +1,235 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Exporter interface {
+	Export(t *Trace)
+}
+
+type LogExporter struct {
+	Logger *slog.Logger
+}
+
+func (e LogExporter) Export(t *Trace) {
+	if t == nil || e.Logger == nil {
+		return
+	}
+	for _, s := range t.Spans() {
+		e.Logger.Debug("span",
+			slog.String("trace_id", t.ID),
+			slog.String("span", s.Name),
+			slog.Duration("duration", s.Duration()),
+		)
+	}
+}
+
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+)
+
+type OTLPExporter struct {
+	Endpoint    string
+	ServiceName string
+	Client      *http.Client
+	Logger      *slog.Logger
+
+	// queue is never closed: Export may still be called from requests
+	// that outlive Close. done tells both sides to stop instead.
+	queue     chan *Trace
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewOTLPExporter(endpoint, serviceName string, logger *slog.Logger) *OTLPExporter {
+	e := &OTLPExporter{
+		Endpoint:    endpoint,
+		ServiceName: serviceName,
+		Client:      &http.Client{Timeout: 5 * time.Second},
+		Logger:      logger,
+		queue:       make(chan *Trace, 256),
+		done:        make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.loop()
+	return e
+}
+
+func (e *OTLPExporter) Export(t *Trace) {
+	if t == nil {
+		return
+	}
+	select {
+	case <-e.done:
+		return
+	default:
+	}
+	select {
+	case e.queue <- t:
+	default:
+		if e.Logger != nil {
+			e.Logger.Warn("otlp export queue full, dropping trace", slog.String("trace_id", t.ID))
+		}
+	}
+}
+
+// Close sends the traces already queued and stops the exporter; traces
+// exported afterwards are dropped.
+func (e *OTLPExporter) Close(ctx context.Context) error {
+	e.closeOnce.Do(func() { close(e.done) })
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OTLPExporter) loop() {
+	defer e.wg.Done()
+	for {
+		select {
+		case t := <-e.queue:
+			e.export(t)
+		case <-e.done:
+			for {
+				select {
+				case t := <-e.queue:
+					e.export(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) export(t *Trace) {
+	if err := e.send(t); err != nil && e.Logger != nil {
+		e.Logger.Warn("otlp export failed", slog.String("trace_id", t.ID), slog.Any("error", err))
+	}
+}
+
+func (e *OTLPExporter) send(t *Trace) error {
+	body, err := json.Marshal(EncodeOTLP(t, e.ServiceName))
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.Client.Post(e.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp collector responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type otlpPayload struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func EncodeOTLP(t *Trace, serviceName string) any {
+	end := t.Start.Add(t.Duration())
+
+	attrs := make([]otlpAttribute, 0, len(t.Attrs()))
+	for _, a := range t.Attrs() {
+		attrs = append(attrs, otlpAttribute{Key: a.Key, Value: otlpValue{StringValue: a.Value}})
+	}
+
+	spans := []otlpSpan{{
+		TraceID:           t.ID,
+		SpanID:            t.SpanID,
+		Name:              t.Name,
+		Kind:              spanKindServer,
+		StartTimeUnixNano: unixNano(t.Start),
+		EndTimeUnixNano:   unixNano(end),
+		Attributes:        attrs,
+	}}
+	for _, s := range t.Spans() {
+		spans = append(spans, otlpSpan{
+			TraceID:           t.ID,
+			SpanID:            s.ID,
+			ParentSpanID:      t.SpanID,
+			Name:              s.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: unixNano(s.Start),
+			EndTimeUnixNano:   unixNano(s.End),
+		})
+	}
+
+	return otlpPayload{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: []otlpAttribute{
+				{Key: "service.name", Value: otlpValue{StringValue: serviceName}},
+			}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: serviceName},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,202 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporterExportAfterClose(t *testing.T) {
+	var received atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		received.Add(1)
+	}))
+	defer collector.Close()
+
+	e := NewOTLPExporter(collector.URL, "test", nil)
+	e.Export(New("before"))
+
+	// Requests still draining after a shutdown timeout keep exporting
+	// while the exporter closes.
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				e.Export(New("during"))
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	e.Export(New("after"))
+	if err := e.Close(ctx); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if received.Load() == 0 {
+		t.Error("collector received nothing; queued traces should be sent before Close returns")
+	}
+}
+
+var update = flag.Bool("update", false, "regenerate testdata/*.golden.json")
+
+func testTrace() *Trace {
+	start := time.Date(2026, time.October, 21, 15, 40, 0, 123456789, time.UTC)
+	return &Trace{
+		ID:     "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID: "00f067aa0ba902b7",
+		Name:   "GET /wallpaper",
+		Start:  start,
+		End:    start.Add(250 * time.Millisecond),
+		spans: []Span{
+			{ID: "a3ce929d0e0e4736", Name: "grid", Start: start.Add(10 * time.Millisecond), End: start.Add(110 * time.Millisecond)},
+			{ID: "b7ad6b7169203331", Name: "encode", Start: start.Add(120 * time.Millisecond), End: start.Add(240*time.Millisecond + 1)},
+		},
+		attrs: []Attr{
+			{Key: "device", Value: "iphone-15"},
+			{Key: "footer", Value: `"quoted" & <escaped>`},
+			{Key: "http.status_code", Value: "200"},
+		},
+	}
+}
+
+func TestEncodeOTLPGolden(t *testing.T) {
+	got, err := json.MarshalIndent(EncodeOTLP(testTrace(), "calendar-wallpaper"), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "otlp_trace.golden.json")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("EncodeOTLP output differs from %s:\n%s", path, got)
+	}
+}
+
+func TestOTLPExporterSendsToCollector(t *testing.T) {
+	type request struct {
+		method, path, contentType string
+		payload                   otlpPayload
+	}
+	requests := make(chan request, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.Path, contentType: r.Header.Get("Content-Type")}
+		if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+			t.Error(err)
+		}
+		requests <- req
+	}))
+	defer collector.Close()
+
+	e := NewOTLPExporter(collector.URL+"/v1/traces", "calendar-wallpaper", nil)
+	defer e.Close(context.Background())
+	e.Export(testTrace())
+
+	var req request
+	select {
+	case req = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("collector received nothing")
+	}
+	if req.method != http.MethodPost || req.path != "/v1/traces" || req.contentType != "application/json" {
+		t.Errorf("request = %s %s (%s)", req.method, req.path, req.contentType)
+	}
+	rs := req.payload.ResourceSpans
+	if len(rs) != 1 || len(rs[0].ScopeSpans) != 1 {
+		t.Fatalf("payload = %+v", req.payload)
+	}
+	spans := rs[0].ScopeSpans[0].Spans
+	if len(spans) != 3 || spans[0].ParentSpanID != "" || spans[1].ParentSpanID != spans[0].SpanID {
+		t.Errorf("spans = %+v", spans)
+	}
+	if got := rs[0].Resource.Attributes; len(got) != 1 || got[0].Value.StringValue != "calendar-wallpaper" {
+		t.Errorf("resource attributes = %+v", got)
+	}
+}
+
+func TestOTLPExporterDropsWhenQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	var received atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if received.Add(1) == 1 {
+			<-release
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	var logs bytes.Buffer
+	var mu sync.Mutex
+	logger := slog.New(slog.NewTextHandler(lockedWriter{&mu, &logs}, nil))
+	e := NewOTLPExporter(collector.URL, "test", logger)
+
+	// The first trace blocks the sender in the collector; the queue then
+	// fills and the rest are dropped.
+	e.Export(New("first"))
+	for received.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	capacity := cap(e.queue)
+	for range capacity + 5 {
+		e.Export(New("queued"))
+	}
+	close(release)
+
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := int(received.Load()); got != 1+capacity {
+		t.Errorf("collector received %d traces, want %d", got, 1+capacity)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if n := strings.Count(logs.String(), "dropping trace"); n != 5 {
+		t.Errorf("logged %d drops, want 5", n)
+	}
+	if !strings.Contains(logs.String(), "status 503") {
+		t.Error("collector errors should be logged")
+	}
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type Attr struct {
+	Key   string
+	Value string
+}
+
+type Span struct {
+	ID    string
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+type Trace struct {
+	ID     string
+	SpanID string
+	Name   string
+	Start  time.Time
+	End    time.Time
+
+	mu    sync.Mutex
+	spans []Span
+	attrs []Attr
+}
+
+func New(name string) *Trace {
+	return &Trace{
+		ID:     randomHex(16),
+		SpanID: randomHex(8),
+		Name:   name,
+		Start:  time.Now(),
+	}
+}
+
+func (t *Trace) Finish() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.End.IsZero() {
+		t.End = time.Now()
+	}
+}
+
+func (t *Trace) Duration() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.End.IsZero() {
+		return time.Since(t.Start)
+	}
+	return t.End.Sub(t.Start)
+}
+
+func (t *Trace) Spans() []Span {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Span(nil), t.spans...)
+}
+
+func (t *Trace) Attrs() []Attr {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Attr(nil), t.attrs...)
+}
+
+func (t *Trace) Annotate(kv ...string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		t.setAttr(kv[i], kv[i+1])
+	}
+}
+
+func (t *Trace) setAttr(key, value string) {
+	for i := range t.attrs {
+		if t.attrs[i].Key == key {
+			t.attrs[i].Value = value
+			return
+		}
+	}
+	t.attrs = append(t.attrs, Attr{Key: key, Value: value})
+}
+
+func (t *Trace) StartSpan(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	span := Span{ID: randomHex(8), Name: name, Start: time.Now()}
+	return func() {
+		span.End = time.Now()
+		t.mu.Lock()
+		t.spans = append(t.spans, span)
+		t.mu.Unlock()
+	}
+}
+
+type ctxKey struct{}
+
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+func FromContext(ctx context.Context) *Trace {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(ctxKey{}).(*Trace)
+	return t
+}
+
+func StartSpan(ctx context.Context, name string) func() {
+	return FromContext(ctx).StartSpan(name)
+}
+
+func Annotate(ctx context.Context, kv ...string) {
+	FromContext(ctx).Annotate(kv...)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"sync"
+
+	"calendar-wallpaper/internal/domain"
+)
+
+// HeatmapStore keeps heatmap data sets by name. Load reports a missing
+// set with an error matching fs.ErrNotExist.
+type HeatmapStore interface {
+	Load(ctx context.Context, name string) (domain.HeatmapValues, error)
+	Save(ctx context.Context, name string, values domain.HeatmapValues) error
+}
+
+var ErrHeatmapsDisabled = errors.New("heatmap storage is not configured")
+
+// heatmapWrites serializes uploads so a merge cannot load a set, lose a
+// race with another upload and save over its days.
+var heatmapWrites sync.Mutex
+
+// loadHeatmap returns the named data set; an unnamed or missing set, or
+// no store at all, renders as an empty grid.
+func (s Service) loadHeatmap(ctx context.Context, name string) (domain.HeatmapValues, error) {
+	if name == "" || s.Heatmaps == nil {
+		return nil, nil
+	}
+	if !domain.ValidHeatmapName(name) {
+		return nil, fmt.Errorf("%w: bad name %q", domain.ErrInvalidHeatmap, name)
+	}
+	values, err := s.Heatmaps.Load(ctx, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return values, err
+}
+
+// SaveHeatmap stores values under name, replacing the set or, with merge,
+// overwriting only the days given.
+func (s Service) SaveHeatmap(ctx context.Context, name string, values domain.HeatmapValues, merge bool) error {
+	if s.Heatmaps == nil {
+		return ErrHeatmapsDisabled
+	}
+	if !domain.ValidHeatmapName(name) {
+		return fmt.Errorf("%w: bad name %q", domain.ErrInvalidHeatmap, name)
+	}
+	heatmapWrites.Lock()
+	defer heatmapWrites.Unlock()
+
+	if merge {
+		existing, err := s.loadHeatmap(ctx, name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			maps.Copy(existing, values)
+			values = existing
+		}
+	}
+	return s.Heatmaps.Save(ctx, name, values)
+}
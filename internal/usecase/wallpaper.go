@@ -1,11 +1,16 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"image"
+	"strconv"
 	"time"
 
 	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/tracing"
 )
 
 type Clock interface {
@@ -18,27 +23,52 @@ func (SystemClock) Now() time.Time {
 	return time.Now()
 }
 
+type FixedClock struct {
+	Time time.Time
+}
+
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
+
 type Renderer interface {
 	RenderCalendar(
+		ctx context.Context,
 		now time.Time,
 		device domain.DeviceProfile,
 		theme domain.Theme,
-		mode string,
-		lang string,
-		weekends string,
+		opts domain.RenderOptions,
+	) *image.RGBA
+}
+
+type RenderObserver interface {
+	ObserveRender(
+		device domain.DeviceProfile,
 		dayStyle domain.DayStyle,
-		uiScale float64,
 		bgStyle domain.BackgroundStyle,
-		bgColor string,
-	) *image.RGBA
+		lang string,
+		weekends string,
+		d time.Duration,
+	)
 }
 
 type Service struct {
 	Clock    Clock
 	Renderer Renderer
 	Theme    domain.Theme
+	Metrics  RenderObserver
+	// Heatmaps holds the data for the heatmap view; without it the view
+	// renders empty and uploads are refused.
+	Heatmaps HeatmapStore
 }
 
+const (
+	minTimezone    = -12
+	maxTimezone    = 14
+	minSizePercent = 80
+	maxSizePercent = 130
+)
+
 type RenderParams struct {
 	DeviceKey   string
 	Lang        string
@@ -48,22 +78,108 @@ type RenderParams struct {
 	SizePercent int
 	BgStyle     string
 	BgColor     string
+	Widgets     string
+	Screen      string
+	HomeStyle   string
+	Preview     bool
+	WeekStart   string
+	WeekNumbers bool
+	Weekdays    bool
+	Mode        string
+	Events      []string
+	// Granularity is day, hour or minute; empty picks the mode's default.
+	Granularity string
+	// Heatmap names the stored data set for the heatmap view.
+	Heatmap      string
+	HeatmapColor string
+
+	// Font and FontWeight apply to all text; the Title, Number and Footer
+	// variants override them for one kind of text.
+	Font         string
+	FontWeight   string
+	TitleFont    string
+	TitleWeight  string
+	NumberFont   string
+	NumberWeight string
+	FooterFont   string
+	FooterWeight string
+
+	// Footer is a template (see domain.Footer.Text); Countdowns holds
+	// "label:YYYY-MM-DD" entries for {countdown:label}.
+	Footer         string
+	FooterPosition string
+	FooterStyle    string
+	Countdowns     []string
+
+	// Ad-hoc device geometry; used instead of DeviceKey when Width or
+	// Height is set.
+	Width        int
+	Height       int
+	ClockRatio   float64
+	ButtonsRatio float64
+	Inset        int
 }
 
-func (s Service) RenderWallpaper(p RenderParams) (*image.RGBA, error) {
-	if s.Clock == nil || s.Renderer == nil {
-		return nil, errors.New("service dependencies are not configured")
+func (p RenderParams) Device() (domain.DeviceProfile, error) {
+	if p.Width == 0 && p.Height == 0 {
+		return ResolveDevice(p.DeviceKey), nil
 	}
+	return domain.CustomDevice(domain.CustomDeviceSpec{
+		Width:        p.Width,
+		Height:       p.Height,
+		ClockRatio:   p.ClockRatio,
+		ButtonsRatio: p.ButtonsRatio,
+		InsetPx:      p.Inset,
+	})
+}
 
-	device, ok := domain.Devices[p.DeviceKey]
-	if !ok {
-		device = domain.Devices["iphone-15"]
+func (p RenderParams) Fonts() domain.FontChoice {
+	base := domain.ParseFontSpec(p.Font, p.FontWeight)
+	return domain.FontChoice{
+		Title:   domain.ParseFontSpec(p.TitleFont, p.TitleWeight).Or(base),
+		Numbers: domain.ParseFontSpec(p.NumberFont, p.NumberWeight).Or(base),
+		Footer:  domain.ParseFontSpec(p.FooterFont, p.FooterWeight).Or(base),
 	}
+}
+
+// Location is the fixed zone of the request's UTC offset.
+func (p RenderParams) Location() *time.Location {
+	return time.FixedZone("user", p.timezone()*3600)
+}
 
-	lang := domain.NormalizeLang(p.Lang)
-	weekends := normalizeWeekends(p.Weekends)
-	dayStyle := domain.ParseDayStyle(p.DayStyle)
-	bgStyle := domain.ParseBackgroundStyle(p.BgStyle)
+// timezone clamps the UTC offset to the range real zones use.
+func (p RenderParams) timezone() int {
+	return clamp(p.Timezone, minTimezone, maxTimezone)
+}
+
+// renderJob is a request with every parameter normalized.
+type renderJob struct {
+	now    time.Time
+	device domain.DeviceProfile
+	opts   domain.RenderOptions
+
+	size, tz    int
+	granularity domain.Granularity
+}
+
+func (s Service) prepare(ctx context.Context, p RenderParams) (renderJob, error) {
+	device, err := p.Device()
+	if err != nil {
+		return renderJob{}, err
+	}
+	mode := domain.ParseRenderMode(p.Mode)
+	var heatmap domain.HeatmapValues
+	if mode == domain.ModeHeatmap {
+		if heatmap, err = s.loadHeatmap(ctx, p.Heatmap); err != nil {
+			return renderJob{}, err
+		}
+	}
+	footer := domain.Footer{
+		Template:   p.Footer,
+		Position:   domain.ParseFooterPosition(p.FooterPosition),
+		Style:      domain.ParseFooterStyle(p.FooterStyle),
+		Countdowns: domain.ParseCountdowns(p.Countdowns),
+	}
 	bgColor := p.BgColor
 	if bgColor == "" {
 		bgColor = "black"
@@ -73,29 +189,133 @@ func (s Service) RenderWallpaper(p RenderParams) (*image.RGBA, error) {
 	if size == 0 {
 		size = 100
 	}
-	if size < 80 {
-		size = 80
-	} else if size > 130 {
-		size = 130
-	}
-	uiScale := float64(size) / 100.0
-
-	loc := time.FixedZone("user", p.Timezone*3600)
-	now := s.Clock.Now().In(loc)
-
-	img := s.Renderer.RenderCalendar(
-		now,
-		device,
-		s.Theme,
-		"months",
-		lang,
-		weekends,
-		dayStyle,
-		uiScale,
-		bgStyle,
-		bgColor,
+	size = clamp(size, minSizePercent, maxSizePercent)
+
+	tz := p.timezone()
+	loc := p.Location()
+	granularity := domain.ParseGranularity(p.Granularity, mode)
+	if p.Preview && p.Granularity == "" {
+		// The preview overlay draws a mock lock-screen clock.
+		granularity = domain.GranularityMinute
+	}
+
+	return renderJob{
+		now:    granularity.Truncate(s.Clock.Now().In(loc)),
+		device: device,
+		opts: domain.RenderOptions{
+			Mode:      mode,
+			Lang:      domain.NormalizeLang(p.Lang),
+			Weekends:  normalizeWeekends(p.Weekends),
+			DayStyle:  domain.ParseDayStyle(p.DayStyle),
+			UIScale:   float64(size) / 100.0,
+			BgStyle:   domain.ParseBackgroundStyle(p.BgStyle),
+			BgColor:   bgColor,
+			Widgets:   domain.ParseWidgetLayout(p.Widgets),
+			Screen:    domain.ParseScreen(p.Screen),
+			HomeStyle: domain.ParseHomeStyle(p.HomeStyle),
+			Preview:   p.Preview,
+			Fonts:     p.Fonts(),
+			Footer:    footer,
+
+			WeekStart:   domain.ParseWeekStart(p.WeekStart),
+			WeekNumbers: p.WeekNumbers,
+
+			WeekdayHeader: p.Weekdays,
+			Events:        domain.ParseEvents(p.Events),
+
+			Heatmap:      heatmap,
+			HeatmapColor: p.HeatmapColor,
+		},
+		size:        size,
+		tz:          tz,
+		granularity: granularity,
+	}, nil
+}
+
+// Wallpaper is a validated render request. Its ETag is known before the
+// image is drawn, so a revalidation can be answered without rendering.
+type Wallpaper struct {
+	s   Service
+	job renderJob
+}
+
+// PrepareWallpaper normalizes p and loads the data the render needs.
+func (s Service) PrepareWallpaper(ctx context.Context, p RenderParams) (Wallpaper, error) {
+	if s.Clock == nil || s.Renderer == nil {
+		return Wallpaper{}, errors.New("service dependencies are not configured")
+	}
+	job, err := s.prepare(ctx, p)
+	if err != nil {
+		return Wallpaper{}, err
+	}
+
+	opts := job.opts
+	// Annotate here rather than in Render so revalidations answered with
+	// 304 are traced with the same parameters.
+	tracing.Annotate(ctx,
+		"device", job.device.Key,
+		"mode", string(opts.Mode),
+		"granularity", string(job.granularity),
+		"lang", opts.Lang,
+		"weekends", opts.Weekends,
+		"style", string(opts.DayStyle),
+		"bg", string(opts.BgStyle),
+		"widgets", string(opts.Widgets),
+		"screen", string(opts.Screen),
+		"size", strconv.Itoa(job.size),
+		"timezone", strconv.Itoa(job.tz),
+		"title_font", opts.Fonts.Title.String(),
+		"number_font", opts.Fonts.Numbers.String(),
+		"footer_font", opts.Fonts.Footer.String(),
+		"footer", string(opts.Footer.Position)+"/"+string(opts.Footer.Style),
 	)
-	return img, nil
+	return Wallpaper{s: s, job: job}, nil
+}
+
+func (s Service) RenderWallpaper(ctx context.Context, p RenderParams) (*image.RGBA, error) {
+	w, err := s.PrepareWallpaper(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return w.Render(ctx), nil
+}
+
+// ETag identifies the image Render returns. It changes once per
+// granularity period, so clients can revalidate cheaply instead of
+// downloading an identical wallpaper. salt stands for whatever else
+// shapes the image, such as the build and its fonts.
+func (w Wallpaper) ETag(salt string) string {
+	job := w.job
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%d|%s|%+v|%+v", salt, job.now.Unix(), job.now.Format(time.RFC3339), job.device, job.opts)
+	etag := fmt.Sprintf(`"%016x"`, h.Sum64())
+	if job.opts.BgStyle == domain.BgNoise {
+		// Every render draws new grain, so the bytes differ while the
+		// wallpaper is the same: only a weak validator is honest.
+		etag = "W/" + etag
+	}
+	return etag
+}
+
+func (w Wallpaper) Render(ctx context.Context) *image.RGBA {
+	s, job := w.s, w.job
+	opts := job.opts
+
+	start := time.Now()
+	img := s.Renderer.RenderCalendar(ctx, job.now, job.device, s.Theme, opts)
+	if s.Metrics != nil {
+		s.Metrics.ObserveRender(job.device, opts.DayStyle, opts.BgStyle, opts.Lang, opts.Weekends, time.Since(start))
+	}
+	return img
+}
+
+func ResolveDevice(key string) domain.DeviceProfile {
+	catalog := domain.Devices()
+	if device, ok := catalog.Lookup(key); ok {
+		return device
+	}
+	device, _ := catalog.Lookup(domain.DefaultDeviceKey)
+	return device
 }
 
 func normalizeWeekends(v string) string {
@@ -106,3 +326,13 @@ func normalizeWeekends(v string) string {
 		return "off"
 	}
 }
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -1,36 +1,244 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"calendar-wallpaper/internal/assets"
+	"calendar-wallpaper/internal/config"
 	"calendar-wallpaper/internal/delivery/httpapi"
+	"calendar-wallpaper/internal/devicecatalog"
 	"calendar-wallpaper/internal/domain"
+	"calendar-wallpaper/internal/heatmapstore"
+	"calendar-wallpaper/internal/metrics"
+	"calendar-wallpaper/internal/ratelimit"
 	"calendar-wallpaper/internal/rendering"
+	"calendar-wallpaper/internal/tracing"
 	"calendar-wallpaper/internal/usecase"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
+const serviceName = "calendar-wallpaper"
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		if err := runRender(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "render:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg := config.Load()
+	logger := newLogger(cfg)
+	slog.SetDefault(logger)
+
+	if err := run(cfg, logger); err != nil {
+		logger.Error("fatal", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func run(cfg config.Config, logger *slog.Logger) error {
+	fsys := assets.New(embeddedAssets, cfg.AssetsDir)
+
+	if err := assets.Verify(fsys); err != nil {
+		return err
+	}
+
+	renderer, err := rendering.NewRenderer(fsys)
+	if err != nil {
+		return err
+	}
+	if err := renderer.SelfTest(); err != nil {
+		return err
+	}
+	logger.Info("fonts loaded", slog.Any("families", renderer.Fonts.Families()))
+	if langs := renderer.Fonts.Uncovered(); len(langs) > 0 {
+		logger.Warn("no installed font covers some languages; add the Noto fonts to "+assets.FallbackFontDir,
+			slog.Any("languages", langs),
+		)
+	}
+
+	if cfg.DevicesFile != "" {
+		catalog, err := devicecatalog.Load(cfg.DevicesFile)
+		if err != nil {
+			return err
+		}
+		domain.SetDevices(catalog)
+		logger.Info("device catalog loaded",
+			slog.String("path", cfg.DevicesFile),
+			slog.Int("devices", catalog.Len()),
+		)
+	}
+
+	m := metrics.New()
+
 	service := usecase.Service{
 		Clock:    usecase.SystemClock{},
-		Renderer: rendering.Renderer{},
+		Renderer: renderer,
 		Theme:    domain.IOSTheme(),
+		Metrics:  m,
+		Heatmaps: heatmapstore.FileStore{Dir: cfg.HeatmapDir},
 	}
 
+	var draining atomic.Bool
+	readiness := func() error {
+		if draining.Load() {
+			return errors.New("shutting down")
+		}
+		return renderer.SelfTest()
+	}
+
+	exporter, closeExporter, err := newTraceExporter(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeExporter()
+
 	router := chi.NewRouter()
-	httpapi.RegisterHandlers(router, httpapi.Handler{Service: service})
+	router.Use(middleware.RequestID)
+	router.Use(httpapi.AccessLog(logger, exporter))
+	router.Use(httpapi.Instrument(m))
+
+	handler := httpapi.Handler{
+		Service:        service,
+		Assets:         fsys,
+		Readiness:      readiness,
+		Metrics:        m,
+		MetricsHandler: m.Handler(),
+		RenderLimits:   renderLimits(cfg),
+		HeatmapToken:   cfg.HeatmapToken,
+		Version:        buildVersion(),
+	}
+	if err := httpapi.RegisterHandlers(router, handler); err != nil {
+		return err
+	}
 
 	server := &http.Server{
-		Addr:              ":8080",
+		Addr:              cfg.Addr,
 		Handler:           router,
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      30 * time.Second,
 		IdleTimeout:       60 * time.Second,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if cfg.DevicesFile != "" {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+
+		go devicecatalog.Watcher{
+			Path:     cfg.DevicesFile,
+			Interval: cfg.DevicesPollInterval,
+			Reload:   hup,
+			Logger:   logger,
+		}.Run(ctx)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", slog.String("addr", cfg.Addr))
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	draining.Store(true)
+	logger.Info("shutting down", slog.Duration("drain_timeout", cfg.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func renderLimits(cfg config.Config) []func(http.Handler) http.Handler {
+	var limits []func(http.Handler) http.Handler
+	if cfg.RateLimitRPS > 0 {
+		limiter := ratelimit.NewLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, nil)
+		limits = append(limits, httpapi.RateLimit(limiter))
+	}
+	if cfg.MaxConcurrent > 0 {
+		sem := ratelimit.NewSemaphore(cfg.MaxConcurrent)
+		limits = append(limits, httpapi.ConcurrencyLimit(sem, cfg.RenderQueueTimeout, time.Second))
+	}
+	return limits
+}
+
+func newLogger(cfg config.Config) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	if strings.EqualFold(cfg.LogFormat, "text") {
+		return slog.New(slog.NewTextHandler(os.Stdout, opts))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, opts))
+}
+
+func newTraceExporter(cfg config.Config, logger *slog.Logger) (tracing.Exporter, func(), error) {
+	switch strings.ToLower(cfg.TraceExport) {
+	case "", "off":
+		return nil, func() {}, nil
+	case "log":
+		return tracing.LogExporter{Logger: logger}, func() {}, nil
+	case "otlp":
+		exp := tracing.NewOTLPExporter(cfg.OTLPEndpoint, serviceName, logger)
+		return exp, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = exp.Close(ctx)
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown TRACE_EXPORT %q", cfg.TraceExport)
+	}
+}
+
+// buildVersion is the module version and VCS revision stamped by go build;
+// a build from a modified tree is marked dirty.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision":
+			version += "+" + s.Value
+		case s.Key == "vcs.modified" && s.Value == "true":
+			version += "-dirty"
+		}
 	}
-	fmt.Println("Listening on :8080")
-	_ = server.ListenAndServe()
+	return version
 }